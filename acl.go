@@ -0,0 +1,209 @@
+package s3
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/beyondstorage/go-storage/v4/services"
+	. "github.com/beyondstorage/go-storage/v4/types"
+)
+
+// All available canned ACLs are listed here.
+const (
+	ObjectCannedACLPrivate                = s3types.ObjectCannedACLPrivate
+	ObjectCannedACLPublicRead             = s3types.ObjectCannedACLPublicRead
+	ObjectCannedACLPublicReadWrite        = s3types.ObjectCannedACLPublicReadWrite
+	ObjectCannedACLAuthenticatedRead      = s3types.ObjectCannedACLAuthenticatedRead
+	ObjectCannedACLAwsExecRead            = s3types.ObjectCannedACLAwsExecRead
+	ObjectCannedACLBucketOwnerRead        = s3types.ObjectCannedACLBucketOwnerRead
+	ObjectCannedACLBucketOwnerFullControl = s3types.ObjectCannedACLBucketOwnerFullControl
+)
+
+// All available grant permissions are listed here.
+const (
+	PermissionFullControl = s3types.PermissionFullControl
+	PermissionWrite       = s3types.PermissionWrite
+	PermissionWriteAcp    = s3types.PermissionWriteAcp
+	PermissionRead        = s3types.PermissionRead
+	PermissionReadAcp     = s3types.PermissionReadAcp
+)
+
+// All available grantee types are listed here.
+const (
+	GranteeTypeCanonicalUser         = s3types.TypeCanonicalUser
+	GranteeTypeAmazonCustomerByEmail = s3types.TypeAmazonCustomerByEmail
+	GranteeTypeGroup                 = s3types.TypeGroup
+)
+
+// ObjectACL is the access control list of an object.
+type ObjectACL struct {
+	// OwnerID is the canonical user ID of the object owner.
+	OwnerID string
+	// OwnerDisplayName is the display name of the object owner.
+	OwnerDisplayName string
+
+	Grants []Grant
+}
+
+// Grant is a permission granted to a grantee.
+type Grant struct {
+	// GranteeType could be GranteeTypeCanonicalUser, GranteeTypeAmazonCustomerByEmail or GranteeTypeGroup.
+	GranteeType s3types.Type
+	// GranteeID is the canonical user ID, only used for GranteeTypeCanonicalUser.
+	GranteeID string
+	// GranteeDisplayName is the display name of the grantee, only returned by GetObjectACL.
+	GranteeDisplayName string
+	// GranteeEmailAddress is the email address, only used for GranteeTypeAmazonCustomerByEmail.
+	GranteeEmailAddress string
+	// GranteeURI is the URI of the group, only used for GranteeTypeGroup.
+	GranteeURI string
+
+	Permission s3types.Permission
+}
+
+// pairStorageObjectACL is the parsed struct for object ACL operations.
+type pairStorageObjectACL struct {
+	pairs []Pair
+	// Optional pairs
+	HasExceptedBucketOwner bool
+	ExceptedBucketOwner    string
+}
+
+func (s *Storage) parsePairStorageObjectACL(opts []Pair) (pairStorageObjectACL, error) {
+	result := pairStorageObjectACL{pairs: opts}
+
+	for _, v := range opts {
+		switch v.Key {
+		case "excepted_bucket_owner":
+			if result.HasExceptedBucketOwner {
+				continue
+			}
+			result.HasExceptedBucketOwner = true
+			result.ExceptedBucketOwner = v.Value.(string)
+		default:
+			return pairStorageObjectACL{}, services.PairUnsupportedError{Pair: v}
+		}
+	}
+
+	return result, nil
+}
+
+// GetObjectACL will return the access control list of the object.
+func (s *Storage) GetObjectACL(path string, pairs ...Pair) (acl *ObjectACL, err error) {
+	ctx := context.Background()
+	return s.GetObjectACLWithContext(ctx, path, pairs...)
+}
+
+// GetObjectACLWithContext will return the access control list of the object.
+func (s *Storage) GetObjectACLWithContext(ctx context.Context, path string, pairs ...Pair) (acl *ObjectACL, err error) {
+	defer func() {
+		err = s.formatError("get_object_acl", err, path)
+	}()
+
+	opt, err := s.parsePairStorageObjectACL(pairs)
+	if err != nil {
+		return
+	}
+	return s.getObjectACL(ctx, strings.ReplaceAll(path, "\\", "/"), opt)
+}
+
+// PutObjectACL will replace the access control list of the object with acl.
+//
+// The owner of acl is required by S3, so it's recommended to modify the acl returned by GetObjectACL.
+func (s *Storage) PutObjectACL(path string, acl *ObjectACL, pairs ...Pair) (err error) {
+	ctx := context.Background()
+	return s.PutObjectACLWithContext(ctx, path, acl, pairs...)
+}
+
+// PutObjectACLWithContext will replace the access control list of the object with acl.
+//
+// The owner of acl is required by S3, so it's recommended to modify the acl returned by GetObjectACL.
+func (s *Storage) PutObjectACLWithContext(ctx context.Context, path string, acl *ObjectACL, pairs ...Pair) (err error) {
+	defer func() {
+		err = s.formatError("put_object_acl", err, path)
+	}()
+
+	opt, err := s.parsePairStorageObjectACL(pairs)
+	if err != nil {
+		return
+	}
+	return s.putObjectACL(ctx, strings.ReplaceAll(path, "\\", "/"), acl, opt)
+}
+
+func (s *Storage) getObjectACL(ctx context.Context, path string, opt pairStorageObjectACL) (acl *ObjectACL, err error) {
+	input := &s3.GetObjectAclInput{
+		Bucket: aws.String(s.name),
+		Key:    aws.String(s.getAbsPath(path)),
+	}
+	if opt.HasExceptedBucketOwner {
+		input.ExpectedBucketOwner = &opt.ExceptedBucketOwner
+	}
+	output, err := s.service.GetObjectAcl(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	acl = &ObjectACL{}
+	if output.Owner != nil {
+		acl.OwnerID = aws.ToString(output.Owner.ID)
+		acl.OwnerDisplayName = aws.ToString(output.Owner.DisplayName)
+	}
+	for _, v := range output.Grants {
+		g := Grant{
+			Permission: v.Permission,
+		}
+		if v.Grantee != nil {
+			g.GranteeType = v.Grantee.Type
+			g.GranteeID = aws.ToString(v.Grantee.ID)
+			g.GranteeDisplayName = aws.ToString(v.Grantee.DisplayName)
+			g.GranteeEmailAddress = aws.ToString(v.Grantee.EmailAddress)
+			g.GranteeURI = aws.ToString(v.Grantee.URI)
+		}
+		acl.Grants = append(acl.Grants, g)
+	}
+	return acl, nil
+}
+
+func (s *Storage) putObjectACL(ctx context.Context, path string, acl *ObjectACL, opt pairStorageObjectACL) (err error) {
+	policy := &s3types.AccessControlPolicy{
+		Owner: &s3types.Owner{
+			ID: aws.String(acl.OwnerID),
+		},
+	}
+	if acl.OwnerDisplayName != "" {
+		policy.Owner.DisplayName = aws.String(acl.OwnerDisplayName)
+	}
+	for _, v := range acl.Grants {
+		grantee := &s3types.Grantee{
+			Type: v.GranteeType,
+		}
+		if v.GranteeID != "" {
+			grantee.ID = aws.String(v.GranteeID)
+		}
+		if v.GranteeEmailAddress != "" {
+			grantee.EmailAddress = aws.String(v.GranteeEmailAddress)
+		}
+		if v.GranteeURI != "" {
+			grantee.URI = aws.String(v.GranteeURI)
+		}
+		policy.Grants = append(policy.Grants, s3types.Grant{
+			Grantee:    grantee,
+			Permission: v.Permission,
+		})
+	}
+
+	input := &s3.PutObjectAclInput{
+		Bucket:              aws.String(s.name),
+		Key:                 aws.String(s.getAbsPath(path)),
+		AccessControlPolicy: policy,
+	}
+	if opt.HasExceptedBucketOwner {
+		input.ExpectedBucketOwner = &opt.ExceptedBucketOwner
+	}
+	_, err = s.service.PutObjectAcl(ctx, input)
+	return err
+}
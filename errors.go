@@ -7,4 +7,26 @@ import (
 var (
 	// ErrServerSideEncryptionCustomerKeyInvalid will be returned while server-side encryption customer key is invalid.
 	ErrServerSideEncryptionCustomerKeyInvalid = services.NewErrorCode("invalid server-side encryption customer key")
+
+	// ErrObjectNotModified will be returned while the object has not been modified, which means
+	// the condition specified by if_none_match or if_modified_since is not met.
+	ErrObjectNotModified = services.NewErrorCode("object not modified")
+	// ErrPreconditionFailed will be returned while the precondition specified by pairs is not met.
+	ErrPreconditionFailed = services.NewErrorCode("precondition failed")
+	// ErrObjectLocked will be returned while the operation is blocked by Object Lock retention or legal hold.
+	ErrObjectLocked = services.NewErrorCode("object locked")
+	// ErrObjectArchived will be returned while reading an object in GLACIER or DEEP_ARCHIVE storage class
+	// which has not been restored.
+	ErrObjectArchived = services.NewErrorCode("object archived")
+	// ErrObjectNotArchived will be returned while restoring an object which is not archived.
+	ErrObjectNotArchived = services.NewErrorCode("object not archived")
+	// ErrRestoreInProgress will be returned while a restore of the object is already in progress.
+	ErrRestoreInProgress = services.NewErrorCode("restore in progress")
+	// ErrLinkCycle will be returned while following links and a link is visited twice.
+	ErrLinkCycle = services.NewErrorCode("link cycle")
+	// ErrLinkDepthExceeded will be returned while following links and the link chain is deeper than the max depth.
+	ErrLinkDepthExceeded = services.NewErrorCode("link depth exceeded")
+	// ErrSelectIncomplete will be returned while the select stream is closed without an End event,
+	// which means the records written may be incomplete.
+	ErrSelectIncomplete = services.NewErrorCode("select incomplete")
 )
@@ -0,0 +1,405 @@
+//go:build go1.16
+// +build go1.16
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/beyondstorage/go-storage/v4/services"
+	typ "github.com/beyondstorage/go-storage/v4/types"
+)
+
+// FS will return a read-only file system of the dir path, which implements fs.FS, fs.StatFS,
+// fs.ReadDirFS and fs.ReadFileFS.
+//
+// Dirs could be either dir markers or prefixes of other objects. Files are read with ranged reads,
+// and implement io.Seeker and io.ReaderAt, so that they could be served by http.FileServer.
+func (s *Storage) FS(path string) fs.FS {
+	return s.FSWithContext(context.Background(), path)
+}
+
+// FSWithContext will return a read-only file system of the dir path, which implements fs.FS, fs.StatFS,
+// fs.ReadDirFS and fs.ReadFileFS. All requests will be sent with ctx.
+//
+// Dirs could be either dir markers or prefixes of other objects. Files are read with ranged reads,
+// and implement io.Seeker and io.ReaderAt, so that they could be served by http.FileServer.
+func (s *Storage) FSWithContext(ctx context.Context, path string) fs.FS {
+	return &storageFS{
+		s:      s,
+		ctx:    ctx,
+		prefix: formatDirPath(strings.ReplaceAll(path, "\\", "/")),
+	}
+}
+
+type storageFS struct {
+	s      *Storage
+	ctx    context.Context
+	prefix string
+}
+
+// Open implements fs.FS.
+func (f *storageFS) Open(name string) (fs.File, error) {
+	info, err := f.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &fsDir{fs: f, name: name, info: info}, nil
+	}
+	return &fsFile{fs: f, name: name, info: info}, nil
+}
+
+// Stat implements fs.StatFS.
+func (f *storageFS) Stat(name string) (fs.FileInfo, error) {
+	return f.stat("stat", name)
+}
+
+// ReadDir implements fs.ReadDirFS.
+func (f *storageFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	info, err := f.stat("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	return f.readDir(name)
+}
+
+// ReadFile implements fs.ReadFileFS.
+func (f *storageFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+
+	var buf bytes.Buffer
+	_, err := f.s.read(f.ctx, f.prefix+name, &buf, pairStorageRead{})
+	if err != nil {
+		return nil, formatFSError("read", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// stat will stat name as a file first, and then as a dir.
+func (f *storageFS) stat(op, name string) (*fsFileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return &fsFileInfo{name: ".", mode: formatFSMode(typ.ModeDir)}, nil
+	}
+
+	o, err := f.s.stat(f.ctx, f.prefix+name, pairStorageStat{})
+	if err == nil {
+		info := &fsFileInfo{
+			name: path.Base(name),
+			mode: formatFSMode(o.Mode),
+		}
+		// Dirs returned by stat have no content length, etag or last modified.
+		info.size, _ = o.GetContentLength()
+		info.etag, _ = o.GetEtag()
+		if v, ok := o.GetLastModified(); ok {
+			// List returns the last modified time in milliseconds while stat returns in seconds.
+			info.modTime = v.Truncate(time.Second)
+		}
+		return info, nil
+	}
+	if !errors.Is(formatError(err), services.ErrObjectNotExist) {
+		return nil, formatFSError(op, name, err)
+	}
+
+	// The dir exists if there is any object under it, including the dir marker.
+	_, exist, err := f.s.statImplicitDir(f.ctx, f.prefix+name, f.s.getAbsPath(f.prefix+name+"/"), pairStorageStat{})
+	if err != nil {
+		return nil, formatFSError(op, name, err)
+	}
+	if !exist {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return &fsFileInfo{name: path.Base(name), mode: formatFSMode(typ.ModeDir)}, nil
+}
+
+// readDir will list the dir with delimiter, and return the entries sorted by name.
+func (f *storageFS) readDir(name string) ([]fs.DirEntry, error) {
+	prefix := f.s.getAbsPath(f.prefix)
+	if name != "." {
+		prefix = f.s.getAbsPath(f.prefix + name + "/")
+	}
+	input := &objectPageStatus{
+		delimiter: "/",
+		maxKeys:   listMaxKeysMaximum,
+		prefix:    prefix,
+	}
+
+	var entries []fs.DirEntry
+	for {
+		// Use the list result directly, as getters of objects returned by list will trigger stat.
+		contents, prefixes, truncated, err := f.s.listObjects(f.ctx, input)
+		if err != nil {
+			return nil, formatFSError("readdir", name, err)
+		}
+		for _, v := range prefixes {
+			base := strings.TrimSuffix(strings.TrimPrefix(aws.ToString(v.Prefix), prefix), "/")
+			if fs.ValidPath(base) && !strings.Contains(base, "/") {
+				entries = append(entries, &fsFileInfo{name: base, mode: formatFSMode(typ.ModeDir)})
+			}
+		}
+		for _, v := range contents {
+			// The dir marker of the dir itself will be listed under its prefix.
+			base := strings.TrimPrefix(aws.ToString(v.Key), prefix)
+			if base == "" || !fs.ValidPath(base) {
+				continue
+			}
+			entries = append(entries, &fsFileInfo{
+				name:    base,
+				mode:    formatFSMode(typ.ModeRead),
+				size:    v.Size,
+				modTime: aws.ToTime(v.LastModified).Truncate(time.Second),
+				etag:    aws.ToString(v.ETag),
+			})
+		}
+		if !truncated {
+			break
+		}
+	}
+
+	// "a/" is listed after "a.txt" by S3, but entries should be sorted by name.
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+// formatFSError will convert errors of storage to errors defined in io/fs.
+func formatFSError(op, name string, err error) error {
+	err = formatError(err)
+	switch {
+	case errors.Is(err, services.ErrObjectNotExist):
+		err = fmt.Errorf("%w: %v", fs.ErrNotExist, err)
+	case errors.Is(err, services.ErrPermissionDenied):
+		err = fmt.Errorf("%w: %v", fs.ErrPermission, err)
+	}
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+// formatFSMode will convert the object mode to a read-only fs.FileMode.
+func formatFSMode(m typ.ObjectMode) fs.FileMode {
+	if m.IsDir() {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+// fsFileInfo implements both fs.FileInfo and fs.DirEntry.
+type fsFileInfo struct {
+	name    string
+	mode    fs.FileMode
+	size    int64
+	modTime time.Time
+	etag    string
+}
+
+func (i *fsFileInfo) Name() string               { return i.name }
+func (i *fsFileInfo) Size() int64                { return i.size }
+func (i *fsFileInfo) Mode() fs.FileMode          { return i.mode }
+func (i *fsFileInfo) ModTime() time.Time         { return i.modTime }
+func (i *fsFileInfo) IsDir() bool                { return i.mode.IsDir() }
+func (i *fsFileInfo) Sys() interface{}           { return nil }
+func (i *fsFileInfo) Type() fs.FileMode          { return i.mode.Type() }
+func (i *fsFileInfo) Info() (fs.FileInfo, error) { return i, nil }
+
+// fsFile is a file opened by storageFS, which will be read with ranged reads.
+type fsFile struct {
+	fs     *storageFS
+	name   string
+	info   *fsFileInfo
+	offset int64
+	// body is the body of the ranged read started from offset, which will be reset by Seek.
+	body   io.ReadCloser
+	closed bool
+}
+
+func (f *fsFile) Stat() (fs.FileInfo, error) {
+	if f.closed {
+		return nil, &fs.PathError{Op: "stat", Path: f.name, Err: fs.ErrClosed}
+	}
+	return f.info, nil
+}
+
+func (f *fsFile) Read(p []byte) (n int, err error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrClosed}
+	}
+	if f.offset >= f.info.size {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	if f.body == nil {
+		input, err := f.fs.s.formatGetObjectInput(f.fs.prefix+f.name, f.readPairs(f.offset))
+		if err != nil {
+			return 0, formatFSError("read", f.name, err)
+		}
+		output, err := f.fs.s.service.GetObject(f.fs.ctx, input)
+		if err != nil {
+			return 0, formatFSError("read", f.name, err)
+		}
+		f.body = output.Body
+	}
+
+	n, err = f.body.Read(p)
+	f.offset += int64(n)
+	if err == io.EOF && f.offset < f.info.size {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (f *fsFile) ReadAt(p []byte, off int64) (n int, err error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrClosed}
+	}
+	if off < 0 {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrInvalid}
+	}
+	if off >= f.info.size {
+		return 0, io.EOF
+	}
+	size := int64(len(p))
+	if off+size > f.info.size {
+		size = f.info.size - off
+	}
+	if size == 0 {
+		return 0, nil
+	}
+
+	opt := f.readPairs(off)
+	opt.HasSize, opt.Size = true, size
+	w := bytes.NewBuffer(p[:0])
+	_, err = f.fs.s.read(f.fs.ctx, f.fs.prefix+f.name, w, opt)
+	n = copy(p, w.Bytes())
+	if err != nil {
+		return n, formatFSError("read", f.name, err)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (f *fsFile) Seek(offset int64, whence int) (int64, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrClosed}
+	}
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.info.size
+	default:
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+
+	if offset != f.offset && f.body != nil {
+		_ = f.body.Close()
+		f.body = nil
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *fsFile) Close() error {
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.name, Err: fs.ErrClosed}
+	}
+	f.closed = true
+	if f.body != nil {
+		return f.body.Close()
+	}
+	return nil
+}
+
+// readPairs will return the pairs to read from offset, which make sure the object is not changed since opened.
+func (f *fsFile) readPairs(offset int64) pairStorageRead {
+	return pairStorageRead{
+		HasOffset:  true,
+		Offset:     offset,
+		HasIfMatch: f.info.etag != "",
+		IfMatch:    f.info.etag,
+	}
+}
+
+// fsDir is a dir opened by storageFS, entries will be listed on the first ReadDir.
+type fsDir struct {
+	fs      *storageFS
+	name    string
+	info    *fsFileInfo
+	entries []fs.DirEntry
+	listed  bool
+	offset  int
+	closed  bool
+}
+
+func (d *fsDir) Stat() (fs.FileInfo, error) {
+	if d.closed {
+		return nil, &fs.PathError{Op: "stat", Path: d.name, Err: fs.ErrClosed}
+	}
+	return d.info, nil
+}
+
+func (d *fsDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+func (d *fsDir) ReadDir(n int) (entries []fs.DirEntry, err error) {
+	if d.closed {
+		return nil, &fs.PathError{Op: "readdir", Path: d.name, Err: fs.ErrClosed}
+	}
+	if !d.listed {
+		d.entries, err = d.fs.readDir(d.name)
+		if err != nil {
+			return nil, err
+		}
+		d.listed = true
+	}
+
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+	return rest[:n], nil
+}
+
+func (d *fsDir) Close() error {
+	if d.closed {
+		return &fs.PathError{Op: "close", Path: d.name, Err: fs.ErrClosed}
+	}
+	d.closed = true
+	return nil
+}
@@ -0,0 +1,98 @@
+//go:build go1.16
+// +build go1.16
+
+package s3
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/aws/smithy-go"
+
+	typ "github.com/beyondstorage/go-storage/v4/types"
+)
+
+func TestFS(t *testing.T) {
+	store := newFakeStorage(t)
+	writeObjects(t, store, map[string]string{
+		"root/a.txt":        "hello, world",
+		"root/b/":           "",
+		"root/b/c.txt":      "c",
+		"root/d/e/f.txt":    "implicit",
+		"root/empty/":       "",
+		"outside/other.txt": "other",
+	})
+	o, err := store.Stat("root/a.txt")
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	fsys := store.FS("root")
+
+	if err = fstest.TestFS(fsys, "a.txt", "b/c.txt", "d/e/f.txt", "empty"); err != nil {
+		t.Fatal(err)
+	}
+
+	fi, err := fs.Stat(fsys, "d")
+	if err != nil || !fi.IsDir() || fi.Mode() != fs.ModeDir|0555 {
+		t.Errorf("stat d: expected implicit dir, actual %v, %v", fi, err)
+	}
+	fi, err = fs.Stat(fsys, "a.txt")
+	if err != nil || fi.Size() != 12 || fi.Mode() != 0444 || !fi.ModTime().Equal(o.MustGetLastModified()) {
+		t.Errorf("stat a.txt: unexpected %v, %v", fi, err)
+	}
+
+	f, err := fsys.Open("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err = f.(io.Seeker).Seek(7, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(f)
+	if err != nil || string(content) != "world" {
+		t.Errorf("read after seek: expected world, actual %q, %v", content, err)
+	}
+	buf := make([]byte, 5)
+	if n, err := f.(io.ReaderAt).ReadAt(buf, 0); n != 5 || err != nil || string(buf) != "hello" {
+		t.Errorf("read at: expected hello, actual %q, %v", buf[:n], err)
+	}
+
+	for _, name := range []string{"missing", "b/missing.txt", "other.txt"} {
+		if _, err = fsys.Open(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("open %s: expected %v, actual %v", name, fs.ErrNotExist, err)
+		}
+	}
+	if _, err = fsys.Open("../outside"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("open ../outside: expected %v, actual %v", fs.ErrInvalid, err)
+	}
+}
+
+func TestFormatFSError(t *testing.T) {
+	err := formatFSError("open", "a", &smithy.GenericAPIError{Code: "AccessDenied", Message: "Access Denied"})
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("expected %v, actual %v", fs.ErrPermission, err)
+	}
+}
+
+func TestFormatFSMode(t *testing.T) {
+	cases := []struct {
+		name     string
+		mode     typ.ObjectMode
+		expected fs.FileMode
+	}{
+		{"dir", typ.ModeDir, fs.ModeDir | 0555},
+		{"read", typ.ModeRead, 0444},
+		{"read and part", typ.ModeRead | typ.ModePart, 0444},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := formatFSMode(tt.mode); actual != tt.expected {
+				t.Errorf("expected %v, actual %v", tt.expected, actual)
+			}
+		})
+	}
+}
@@ -28,6 +28,18 @@ const Type = "s3"
 
 // ObjectSystemMetadata stores system metadata for object.
 type ObjectSystemMetadata struct {
+	CacheControl                          string
+	ContentDisposition                    string
+	ContentEncoding                       string
+	ContentLanguage                       string
+	Expires                               time.Time
+	ObjectLockLegalHold                   bool
+	ObjectLockMode                        string
+	ObjectLockRetainUntilDate             time.Time
+	OwnerDisplayName                      string
+	OwnerID                               string
+	RestoreExpiryDate                     time.Time
+	RestoreOngoing                        bool
 	ServerSideEncryption                  string
 	ServerSideEncryptionAwsKmsKeyID       string
 	ServerSideEncryptionBucketKeyEnabled  bool
@@ -35,6 +47,7 @@ type ObjectSystemMetadata struct {
 	ServerSideEncryptionCustomerAlgorithm string
 	ServerSideEncryptionCustomerKeyMd5    string
 	StorageClass                          string
+	TagCount                              int32
 }
 
 // GetObjectSystemMetadata will get ObjectSystemMetadata from Object.
@@ -58,6 +71,18 @@ func setObjectSystemMetadata(o *Object, sm ObjectSystemMetadata) {
 
 // StorageSystemMetadata stores system metadata for object.
 type StorageSystemMetadata struct {
+	CacheControl                          string
+	ContentDisposition                    string
+	ContentEncoding                       string
+	ContentLanguage                       string
+	Expires                               time.Time
+	ObjectLockLegalHold                   bool
+	ObjectLockMode                        string
+	ObjectLockRetainUntilDate             time.Time
+	OwnerDisplayName                      string
+	OwnerID                               string
+	RestoreExpiryDate                     time.Time
+	RestoreOngoing                        bool
 	ServerSideEncryption                  string
 	ServerSideEncryptionAwsKmsKeyID       string
 	ServerSideEncryptionBucketKeyEnabled  bool
@@ -65,6 +90,7 @@ type StorageSystemMetadata struct {
 	ServerSideEncryptionCustomerAlgorithm string
 	ServerSideEncryptionCustomerKeyMd5    string
 	StorageClass                          string
+	TagCount                              int32
 }
 
 // GetStorageSystemMetadata will get StorageSystemMetadata from Storage.
@@ -86,6 +112,91 @@ func setStorageSystemMetadata(s *StorageMeta, sm StorageSystemMetadata) {
 	s.SetSystemMetadata(sm)
 }
 
+// WithACL will apply acl value to Options.
+//
+// specifies the canned ACL to apply to the object, for example `private`, `public-read` and `bucket-owner-full-control`
+func WithACL(v string) Pair {
+	return Pair{Key: "acl", Value: v}
+}
+
+// WithBypassGovernanceRetention will apply bypass_governance_retention value to Options.
+//
+// indicates whether the operation should bypass Governance-mode restrictions of Object Lock,
+// which requires the `s3:BypassGovernanceRetention` permission
+func WithBypassGovernanceRetention() Pair {
+	return Pair{Key: "bypass_governance_retention", Value: true}
+}
+
+// WithCacheControl will apply cache_control value to Options.
+//
+// specifies the `Cache-Control` header of the object
+func WithCacheControl(v string) Pair {
+	return Pair{Key: "cache_control", Value: v}
+}
+
+// WithConcurrency will apply concurrency value to Options.
+//
+// specifies the number of objects to be processed concurrently in bulk operations
+func WithConcurrency(v int) Pair {
+	return Pair{Key: "concurrency", Value: v}
+}
+
+// WithContentDisposition will apply content_disposition value to Options.
+//
+// specifies the `Content-Disposition` header of the object
+func WithContentDisposition(v string) Pair {
+	return Pair{Key: "content_disposition", Value: v}
+}
+
+// WithContentEncoding will apply content_encoding value to Options.
+//
+// specifies the `Content-Encoding` header of the object
+func WithContentEncoding(v string) Pair {
+	return Pair{Key: "content_encoding", Value: v}
+}
+
+// WithContentLanguage will apply content_language value to Options.
+//
+// specifies the `Content-Language` header of the object
+func WithContentLanguage(v string) Pair {
+	return Pair{Key: "content_language", Value: v}
+}
+
+// WithDefaultCacheControl will apply default_cache_control value to Options.
+//
+// specifies the `Cache-Control` header of the object
+func WithDefaultCacheControl(v string) Pair {
+	return Pair{Key: "default_cache_control", Value: v}
+}
+
+// WithDefaultContentDisposition will apply default_content_disposition value to Options.
+//
+// specifies the `Content-Disposition` header of the object
+func WithDefaultContentDisposition(v string) Pair {
+	return Pair{Key: "default_content_disposition", Value: v}
+}
+
+// WithDefaultContentEncoding will apply default_content_encoding value to Options.
+//
+// specifies the `Content-Encoding` header of the object
+func WithDefaultContentEncoding(v string) Pair {
+	return Pair{Key: "default_content_encoding", Value: v}
+}
+
+// WithDefaultContentLanguage will apply default_content_language value to Options.
+//
+// specifies the `Content-Language` header of the object
+func WithDefaultContentLanguage(v string) Pair {
+	return Pair{Key: "default_content_language", Value: v}
+}
+
+// WithDefaultExpires will apply default_expires value to Options.
+//
+// specifies the `Expires` header of the object
+func WithDefaultExpires(v time.Time) Pair {
+	return Pair{Key: "default_expires", Value: v}
+}
+
 // WithDefaultServicePairs will apply default_service_pairs value to Options.
 func WithDefaultServicePairs(v DefaultServicePairs) Pair {
 	return Pair{Key: "default_service_pairs", Value: v}
@@ -109,6 +220,13 @@ func WithDisable100Continue() Pair {
 	return Pair{Key: "disable_100_continue", Value: true}
 }
 
+// WithDryRun will apply dry_run value to Options.
+//
+// specifies whether bulk operations will only report the actions without executing them
+func WithDryRun() Pair {
+	return Pair{Key: "dry_run", Value: true}
+}
+
 // WithEnableVirtualDir will apply enable_virtual_dir value to Options.
 //
 // virtual_dir feature is designed for a service that doesn't have native dir support but wants to
@@ -137,6 +255,14 @@ func WithEnableVirtualLink() Pair {
 	return Pair{Key: "enable_virtual_link", Value: true}
 }
 
+// WithEncodingType will apply encoding_type value to Options.
+//
+// specifies the encoding of object keys in the list response, only `url` is supported. Keys will be
+// decoded automatically, which allows keys containing control characters to be listed.
+func WithEncodingType(v string) Pair {
+	return Pair{Key: "encoding_type", Value: v}
+}
+
 // WithExceptedBucketOwner will apply excepted_bucket_owner value to Options.
 //
 // the account ID of the excepted bucket owner
@@ -144,6 +270,47 @@ func WithExceptedBucketOwner(v string) Pair {
 	return Pair{Key: "excepted_bucket_owner", Value: v}
 }
 
+// WithExclude will apply exclude value to Options.
+//
+// specifies the glob pattern of paths to be skipped, see `path.Match` for the syntax. Patterns without
+// `/` match the base name, others match the whole path
+func WithExclude(v string) Pair {
+	return Pair{Key: "exclude", Value: v}
+}
+
+// WithExpires will apply expires value to Options.
+//
+// specifies the `Expires` header of the object
+func WithExpires(v time.Time) Pair {
+	return Pair{Key: "expires", Value: v}
+}
+
+// WithFetchOwner will apply fetch_owner value to Options.
+//
+// specifies whether the owner of objects will be returned in list, which will be set to `OwnerID` and
+// `OwnerDisplayName` of the object's system metadata
+func WithFetchOwner() Pair {
+	return Pair{Key: "fetch_owner", Value: true}
+}
+
+// WithFollowLinks will apply follow_links value to Options.
+//
+// specifies whether virtual links will be followed, which makes the operation apply to the final
+// target of the link chain. ErrLinkCycle will be returned if a cycle is detected. Targets starting
+// with `./` or `../` are stored as-is by create_link and resolved from the dir of the link, other targets
+// are resolved from the work dir.
+func WithFollowLinks() Pair {
+	return Pair{Key: "follow_links", Value: true}
+}
+
+// WithFollowLinksMaxDepth will apply follow_links_max_depth value to Options.
+//
+// specifies the max number of links to follow, 8 by default. ErrLinkDepthExceeded will be returned
+// if the link chain is deeper.
+func WithFollowLinksMaxDepth(v int) Pair {
+	return Pair{Key: "follow_links_max_depth", Value: v}
+}
+
 // WithForcePathStyle will apply force_path_style value to Options.
 //
 // see http://docs.aws.amazon.com/AmazonS3/latest/dev/VirtualHosting.html for Amazon S3:
@@ -152,6 +319,226 @@ func WithForcePathStyle() Pair {
 	return Pair{Key: "force_path_style", Value: true}
 }
 
+// WithGrantFullControl will apply grant_full_control value to Options.
+//
+// gives the grantee READ, READ_ACP, and WRITE_ACP permissions on the object, for example `id="canonical-user-id"`
+// or `emailAddress="user@example.com"`
+func WithGrantFullControl(v string) Pair {
+	return Pair{Key: "grant_full_control", Value: v}
+}
+
+// WithGrantRead will apply grant_read value to Options.
+//
+// allows grantee to read the object data and its metadata
+func WithGrantRead(v string) Pair {
+	return Pair{Key: "grant_read", Value: v}
+}
+
+// WithGrantReadAcp will apply grant_read_acp value to Options.
+//
+// allows grantee to read the object ACL
+func WithGrantReadAcp(v string) Pair {
+	return Pair{Key: "grant_read_acp", Value: v}
+}
+
+// WithGrantWriteAcp will apply grant_write_acp value to Options.
+//
+// allows grantee to write the ACL for the applicable object
+func WithGrantWriteAcp(v string) Pair {
+	return Pair{Key: "grant_write_acp", Value: v}
+}
+
+// WithHTTPRedirect will apply http_redirect value to Options.
+//
+// specifies the handler will redirect clients to a signed url expiring after the duration instead
+// of proxying the content
+func WithHTTPRedirect(v time.Duration) Pair {
+	return Pair{Key: "http_redirect", Value: v}
+}
+
+// WithIfMatch will apply if_match value to Options.
+//
+// for read and stat, return the object only if its entity tag (ETag) is the same as the one specified.
+// For write and complete_multipart, write the object only if the existing object's ETag is the same
+// as the one specified. Otherwise `ErrPreconditionFailed` will be returned.
+func WithIfMatch(v string) Pair {
+	return Pair{Key: "if_match", Value: v}
+}
+
+// WithIfModifiedSince will apply if_modified_since value to Options.
+//
+// return the object only if it has been modified since the specified time, otherwise return `ErrObjectNotModified`
+func WithIfModifiedSince(v time.Time) Pair {
+	return Pair{Key: "if_modified_since", Value: v}
+}
+
+// WithIfNoneMatch will apply if_none_match value to Options.
+//
+// for read and stat, return the object only if its entity tag (ETag) is different from the one specified,
+// otherwise `ErrObjectNotModified` will be returned. For write and complete_multipart, only
+// `*` is supported, which means write the object only if it doesn't exist, otherwise `ErrPreconditionFailed`
+// will be returned.
+func WithIfNoneMatch(v string) Pair {
+	return Pair{Key: "if_none_match", Value: v}
+}
+
+// WithIfUnmodifiedSince will apply if_unmodified_since value to Options.
+//
+// return the object only if it has not been modified since the specified time, otherwise return `ErrPreconditionFailed`
+func WithIfUnmodifiedSince(v time.Time) Pair {
+	return Pair{Key: "if_unmodified_since", Value: v}
+}
+
+// WithImplicitDir will apply implicit_dir value to Options.
+//
+// specifies whether create_dir will skip writing the dir marker object, the dir will exist implicitly
+// once it has children. Other pairs of create_dir will be ignored.
+func WithImplicitDir() Pair {
+	return Pair{Key: "implicit_dir", Value: true}
+}
+
+// WithInclude will apply include value to Options.
+//
+// specifies the glob pattern of paths to be processed, see `path.Match` for the syntax. Patterns
+// without `/` match the base name, others match the whole path
+func WithInclude(v string) Pair {
+	return Pair{Key: "include", Value: v}
+}
+
+// WithMaxKeys will apply max_keys value to Options.
+//
+// specifies the maximum number of objects returned in each page of list, up to 1000, 200 by default
+func WithMaxKeys(v int32) Pair {
+	return Pair{Key: "max_keys", Value: v}
+}
+
+// WithObjectLockLegalHold will apply object_lock_legal_hold value to Options.
+//
+// specifies whether a legal hold will be applied to the object
+func WithObjectLockLegalHold() Pair {
+	return Pair{Key: "object_lock_legal_hold", Value: true}
+}
+
+// WithObjectLockMode will apply object_lock_mode value to Options.
+//
+// specifies the Object Lock mode that you want to apply to the object, could be `GOVERNANCE` or `COMPLIANCE`.
+// S3 requires `content_md5` for writes with Object Lock.
+func WithObjectLockMode(v string) Pair {
+	return Pair{Key: "object_lock_mode", Value: v}
+}
+
+// WithObjectLockRetainUntilDate will apply object_lock_retain_until_date value to Options.
+//
+// specifies the date and time when you want the Object Lock to expire
+func WithObjectLockRetainUntilDate(v time.Time) Pair {
+	return Pair{Key: "object_lock_retain_until_date", Value: v}
+}
+
+// WithPreserveMetadata will apply preserve_metadata value to Options.
+//
+// specifies whether mirror will preserve the user metadata and system metadata like `Content-Type`
+// of objects
+func WithPreserveMetadata() Pair {
+	return Pair{Key: "preserve_metadata", Value: true}
+}
+
+// WithPreserveStorageClass will apply preserve_storage_class value to Options.
+//
+// specifies whether mirror will preserve the storage class of objects, otherwise the default storage
+// class of the destination will be used
+func WithPreserveStorageClass() Pair {
+	return Pair{Key: "preserve_storage_class", Value: true}
+}
+
+// WithPreserveTagging will apply preserve_tagging value to Options.
+//
+// specifies whether mirror will preserve the tags of objects
+func WithPreserveTagging() Pair {
+	return Pair{Key: "preserve_tagging", Value: true}
+}
+
+// WithQuerySignEndpoint will apply query_sign_endpoint value to Options.
+//
+// the endpoint used in presigned URLs instead of the S3 endpoint, for example `https:cdn.example.com`
+// for a CNAME bucket or reverse proxy. The signature is calculated against this host, so the request
+// must reach S3 with the same `Host` header.
+func WithQuerySignEndpoint(v string) Pair {
+	return Pair{Key: "query_sign_endpoint", Value: v}
+}
+
+// WithResolveMode will apply resolve_mode value to Options.
+//
+// specifies whether list will resolve the real mode of objects, dir markers will be returned as `ModeDir`
+// and links as `ModeLink` with their target. Empty objects will be checked with concurrent HEAD requests,
+// use `concurrency` to control the concurrency.
+func WithResolveMode() Pair {
+	return Pair{Key: "resolve_mode", Value: true}
+}
+
+// WithResponseCacheControl will apply response_cache_control value to Options.
+//
+// sets the `Cache-Control` header of the response
+func WithResponseCacheControl(v string) Pair {
+	return Pair{Key: "response_cache_control", Value: v}
+}
+
+// WithResponseContentDisposition will apply response_content_disposition value to Options.
+//
+// sets the `Content-Disposition` header of the response, for example `attachment; filename="example.txt"`
+func WithResponseContentDisposition(v string) Pair {
+	return Pair{Key: "response_content_disposition", Value: v}
+}
+
+// WithResponseContentEncoding will apply response_content_encoding value to Options.
+//
+// sets the `Content-Encoding` header of the response
+func WithResponseContentEncoding(v string) Pair {
+	return Pair{Key: "response_content_encoding", Value: v}
+}
+
+// WithResponseContentLanguage will apply response_content_language value to Options.
+//
+// sets the `Content-Language` header of the response
+func WithResponseContentLanguage(v string) Pair {
+	return Pair{Key: "response_content_language", Value: v}
+}
+
+// WithResponseContentType will apply response_content_type value to Options.
+//
+// sets the `Content-Type` header of the response
+func WithResponseContentType(v string) Pair {
+	return Pair{Key: "response_content_type", Value: v}
+}
+
+// WithResponseExpires will apply response_expires value to Options.
+//
+// sets the `Expires` header of the response
+func WithResponseExpires(v time.Time) Pair {
+	return Pair{Key: "response_expires", Value: v}
+}
+
+// WithRestoreDays will apply restore_days value to Options.
+//
+// specifies the number of days that the restored copy of an archived object will be available, required
+// for `GLACIER` and `DEEP_ARCHIVE` objects
+func WithRestoreDays(v int32) Pair {
+	return Pair{Key: "restore_days", Value: v}
+}
+
+// WithRestoreTier will apply restore_tier value to Options.
+//
+// specifies the retrieval tier of restore, could be `Expedited`, `Standard` or `Bulk`
+func WithRestoreTier(v string) Pair {
+	return Pair{Key: "restore_tier", Value: v}
+}
+
+// WithSelectStatsCallback will apply select_stats_callback value to Options.
+//
+// specifies the callback of the Progress and Stats events of SelectObjectContent
+func WithSelectStatsCallback(v func(SelectStats)) Pair {
+	return Pair{Key: "select_stats_callback", Value: v}
+}
+
 // WithServerSideEncryption will apply server_side_encryption value to Options.
 //
 // the server-side encryption algorithm used when storing this object in Amazon
@@ -206,6 +593,13 @@ func WithServiceFeatures(v ServiceFeatures) Pair {
 	return Pair{Key: "service_features", Value: v}
 }
 
+// WithStartAfter will apply start_after value to Options.
+//
+// specifies the path to start listing after, which could be any path in the storage
+func WithStartAfter(v string) Pair {
+	return Pair{Key: "start_after", Value: v}
+}
+
 // WithStorageClass will apply storage_class value to Options.
 func WithStorageClass(v string) Pair {
 	return Pair{Key: "storage_class", Value: v}
@@ -216,6 +610,42 @@ func WithStorageFeatures(v StorageFeatures) Pair {
 	return Pair{Key: "storage_features", Value: v}
 }
 
+// WithSyncCallback will apply sync_callback value to Options.
+//
+// specifies the callback of every file processed by sync, which will be called one by one
+func WithSyncCallback(v func(SyncEvent)) Pair {
+	return Pair{Key: "sync_callback", Value: v}
+}
+
+// WithSyncCompare will apply sync_compare value to Options.
+//
+// specifies how sync compares files, `size_mtime` by default, available values are `size_mtime`
+// and `md5`
+func WithSyncCompare(v string) Pair {
+	return Pair{Key: "sync_compare", Value: v}
+}
+
+// WithSyncDelete will apply sync_delete value to Options.
+//
+// specifies whether sync will delete files which don't exist in the source
+func WithSyncDelete() Pair {
+	return Pair{Key: "sync_delete", Value: true}
+}
+
+// WithTagging will apply tagging value to Options.
+//
+// specifies the tag-set of the object, which will be sent as `x-amz-tagging` header
+func WithTagging(v map[string]string) Pair {
+	return Pair{Key: "tagging", Value: v}
+}
+
+// WithUsageDepth will apply usage_depth value to Options.
+//
+// specifies the depth of prefixes to group usage by, 0 means no grouping by prefix, 1 by default
+func WithUsageDepth(v int) Pair {
+	return Pair{Key: "usage_depth", Value: v}
+}
+
 // WithUseAccelerate will apply use_accelerate value to Options.
 //
 // set this to `true` to enable S3 Accelerate feature
@@ -231,7 +661,31 @@ func WithUseArnRegion() Pair {
 	return Pair{Key: "use_arn_region", Value: true}
 }
 
-var pairMap = map[string]string{"content_md5": "string", "content_type": "string", "context": "context.Context", "continuation_token": "string", "credential": "string", "default_content_type": "string", "default_io_callback": "func([]byte)", "default_service_pairs": "DefaultServicePairs", "default_storage_class": "string", "default_storage_pairs": "DefaultStoragePairs", "disable_100_continue": "bool", "enable_virtual_dir": "bool", "enable_virtual_link": "bool", "endpoint": "string", "excepted_bucket_owner": "string", "expire": "time.Duration", "force_path_style": "bool", "http_client_options": "*httpclient.Options", "interceptor": "Interceptor", "io_callback": "func([]byte)", "list_mode": "ListMode", "location": "string", "multipart_id": "string", "name": "string", "object_mode": "ObjectMode", "offset": "int64", "server_side_encryption": "string", "server_side_encryption_aws_kms_key_id": "string", "server_side_encryption_bucket_key_enabled": "bool", "server_side_encryption_context": "string", "server_side_encryption_customer_algorithm": "string", "server_side_encryption_customer_key": "[]byte", "service_features": "ServiceFeatures", "size": "int64", "storage_class": "string", "storage_features": "StorageFeatures", "use_accelerate": "bool", "use_arn_region": "bool", "work_dir": "string"}
+// WithUseListObjectsV1 will apply use_list_objects_v1 value to Options.
+//
+// set this to `true` to list objects with ListObjects (v1) instead of ListObjectsV2, which is required
+// by S3 compatible services without ListObjectsV2 support
+func WithUseListObjectsV1() Pair {
+	return Pair{Key: "use_list_objects_v1", Value: true}
+}
+
+// WithUserMetadata will apply user_metadata value to Options.
+//
+// specifies the user-defined metadata of the object, which will be sent as `x-amz-meta-*` headers.
+// S3 will store keys in lower case.
+func WithUserMetadata(v map[string]string) Pair {
+	return Pair{Key: "user_metadata", Value: v}
+}
+
+// WithUserMetadataCallback will apply user_metadata_callback value to Options.
+//
+// specifies the callback of the user-defined metadata of the object, which will be called by read
+// before the content is written
+func WithUserMetadataCallback(v func(map[string]string)) Pair {
+	return Pair{Key: "user_metadata_callback", Value: v}
+}
+
+var pairMap = map[string]string{"acl": "string", "bypass_governance_retention": "bool", "cache_control": "string", "concurrency": "int", "content_disposition": "string", "content_encoding": "string", "content_language": "string", "content_md5": "string", "content_type": "string", "context": "context.Context", "continuation_token": "string", "credential": "string", "default_cache_control": "string", "default_content_disposition": "string", "default_content_encoding": "string", "default_content_language": "string", "default_content_type": "string", "default_expires": "time.Time", "default_io_callback": "func([]byte)", "default_service_pairs": "DefaultServicePairs", "default_storage_class": "string", "default_storage_pairs": "DefaultStoragePairs", "disable_100_continue": "bool", "dry_run": "bool", "enable_virtual_dir": "bool", "enable_virtual_link": "bool", "encoding_type": "string", "endpoint": "string", "excepted_bucket_owner": "string", "exclude": "string", "expire": "time.Duration", "expires": "time.Time", "fetch_owner": "bool", "follow_links": "bool", "follow_links_max_depth": "int", "force_path_style": "bool", "grant_full_control": "string", "grant_read": "string", "grant_read_acp": "string", "grant_write_acp": "string", "http_client_options": "*httpclient.Options", "http_redirect": "time.Duration", "if_match": "string", "if_modified_since": "time.Time", "if_none_match": "string", "if_unmodified_since": "time.Time", "implicit_dir": "bool", "include": "string", "interceptor": "Interceptor", "io_callback": "func([]byte)", "list_mode": "ListMode", "location": "string", "max_keys": "int32", "multipart_id": "string", "name": "string", "object_lock_legal_hold": "bool", "object_lock_mode": "string", "object_lock_retain_until_date": "time.Time", "object_mode": "ObjectMode", "offset": "int64", "preserve_metadata": "bool", "preserve_storage_class": "bool", "preserve_tagging": "bool", "query_sign_endpoint": "string", "resolve_mode": "bool", "response_cache_control": "string", "response_content_disposition": "string", "response_content_encoding": "string", "response_content_language": "string", "response_content_type": "string", "response_expires": "time.Time", "restore_days": "int32", "restore_tier": "string", "select_stats_callback": "func(SelectStats)", "server_side_encryption": "string", "server_side_encryption_aws_kms_key_id": "string", "server_side_encryption_bucket_key_enabled": "bool", "server_side_encryption_context": "string", "server_side_encryption_customer_algorithm": "string", "server_side_encryption_customer_key": "[]byte", "service_features": "ServiceFeatures", "size": "int64", "start_after": "string", "storage_class": "string", "storage_features": "StorageFeatures", "sync_callback": "func(SyncEvent)", "sync_compare": "string", "sync_delete": "bool", "tagging": "map[string]string", "usage_depth": "int", "use_accelerate": "bool", "use_arn_region": "bool", "use_list_objects_v1": "bool", "user_metadata": "map[string]string", "user_metadata_callback": "func(map[string]string)", "work_dir": "string"}
 var _ Servicer = &Service{}
 
 type ServiceFeatures struct {
@@ -573,18 +1027,32 @@ type pairStorageNew struct {
 	HasName     bool
 	Name        string
 	// Optional pairs
-	HasDefaultContentType  bool
-	DefaultContentType     string
-	HasDefaultIoCallback   bool
-	DefaultIoCallback      func([]byte)
-	HasDefaultStorageClass bool
-	DefaultStorageClass    string
-	HasDefaultStoragePairs bool
-	DefaultStoragePairs    DefaultStoragePairs
-	HasStorageFeatures     bool
-	StorageFeatures        StorageFeatures
-	HasWorkDir             bool
-	WorkDir                string
+	HasDefaultCacheControl       bool
+	DefaultCacheControl          string
+	HasDefaultContentDisposition bool
+	DefaultContentDisposition    string
+	HasDefaultContentEncoding    bool
+	DefaultContentEncoding       string
+	HasDefaultContentLanguage    bool
+	DefaultContentLanguage       string
+	HasDefaultContentType        bool
+	DefaultContentType           string
+	HasDefaultExpires            bool
+	DefaultExpires               time.Time
+	HasDefaultIoCallback         bool
+	DefaultIoCallback            func([]byte)
+	HasDefaultStorageClass       bool
+	DefaultStorageClass          string
+	HasDefaultStoragePairs       bool
+	DefaultStoragePairs          DefaultStoragePairs
+	HasQuerySignEndpoint         bool
+	QuerySignEndpoint            string
+	HasStorageFeatures           bool
+	StorageFeatures              StorageFeatures
+	HasUseListObjectsV1          bool
+	UseListObjectsV1             bool
+	HasWorkDir                   bool
+	WorkDir                      string
 	// Enable features
 	hasEnableVirtualDir  bool
 	EnableVirtualDir     bool
@@ -611,12 +1079,42 @@ func parsePairStorageNew(opts []Pair) (pairStorageNew, error) {
 			}
 			result.HasName = true
 			result.Name = v.Value.(string)
+		case "default_cache_control":
+			if result.HasDefaultCacheControl {
+				continue
+			}
+			result.HasDefaultCacheControl = true
+			result.DefaultCacheControl = v.Value.(string)
+		case "default_content_disposition":
+			if result.HasDefaultContentDisposition {
+				continue
+			}
+			result.HasDefaultContentDisposition = true
+			result.DefaultContentDisposition = v.Value.(string)
+		case "default_content_encoding":
+			if result.HasDefaultContentEncoding {
+				continue
+			}
+			result.HasDefaultContentEncoding = true
+			result.DefaultContentEncoding = v.Value.(string)
+		case "default_content_language":
+			if result.HasDefaultContentLanguage {
+				continue
+			}
+			result.HasDefaultContentLanguage = true
+			result.DefaultContentLanguage = v.Value.(string)
 		case "default_content_type":
 			if result.HasDefaultContentType {
 				continue
 			}
 			result.HasDefaultContentType = true
 			result.DefaultContentType = v.Value.(string)
+		case "default_expires":
+			if result.HasDefaultExpires {
+				continue
+			}
+			result.HasDefaultExpires = true
+			result.DefaultExpires = v.Value.(time.Time)
 		case "default_io_callback":
 			if result.HasDefaultIoCallback {
 				continue
@@ -635,12 +1133,24 @@ func parsePairStorageNew(opts []Pair) (pairStorageNew, error) {
 			}
 			result.HasDefaultStoragePairs = true
 			result.DefaultStoragePairs = v.Value.(DefaultStoragePairs)
+		case "query_sign_endpoint":
+			if result.HasQuerySignEndpoint {
+				continue
+			}
+			result.HasQuerySignEndpoint = true
+			result.QuerySignEndpoint = v.Value.(string)
 		case "storage_features":
 			if result.HasStorageFeatures {
 				continue
 			}
 			result.HasStorageFeatures = true
 			result.StorageFeatures = v.Value.(StorageFeatures)
+		case "use_list_objects_v1":
+			if result.HasUseListObjectsV1 {
+				continue
+			}
+			result.HasUseListObjectsV1 = true
+			result.UseListObjectsV1 = v.Value.(bool)
 		case "work_dir":
 			if result.HasWorkDir {
 				continue
@@ -671,11 +1181,42 @@ func parsePairStorageNew(opts []Pair) (pairStorageNew, error) {
 		result.StorageFeatures.VirtualLink = true
 	}
 	// Default pairs
+	if result.HasDefaultCacheControl {
+		result.HasDefaultStoragePairs = true
+		result.DefaultStoragePairs.CreateMultipart = append(result.DefaultStoragePairs.CreateMultipart, WithCacheControl(result.DefaultCacheControl))
+		result.DefaultStoragePairs.QuerySignHTTPWrite = append(result.DefaultStoragePairs.QuerySignHTTPWrite, WithCacheControl(result.DefaultCacheControl))
+		result.DefaultStoragePairs.Write = append(result.DefaultStoragePairs.Write, WithCacheControl(result.DefaultCacheControl))
+	}
+	if result.HasDefaultContentDisposition {
+		result.HasDefaultStoragePairs = true
+		result.DefaultStoragePairs.CreateMultipart = append(result.DefaultStoragePairs.CreateMultipart, WithContentDisposition(result.DefaultContentDisposition))
+		result.DefaultStoragePairs.QuerySignHTTPWrite = append(result.DefaultStoragePairs.QuerySignHTTPWrite, WithContentDisposition(result.DefaultContentDisposition))
+		result.DefaultStoragePairs.Write = append(result.DefaultStoragePairs.Write, WithContentDisposition(result.DefaultContentDisposition))
+	}
+	if result.HasDefaultContentEncoding {
+		result.HasDefaultStoragePairs = true
+		result.DefaultStoragePairs.CreateMultipart = append(result.DefaultStoragePairs.CreateMultipart, WithContentEncoding(result.DefaultContentEncoding))
+		result.DefaultStoragePairs.QuerySignHTTPWrite = append(result.DefaultStoragePairs.QuerySignHTTPWrite, WithContentEncoding(result.DefaultContentEncoding))
+		result.DefaultStoragePairs.Write = append(result.DefaultStoragePairs.Write, WithContentEncoding(result.DefaultContentEncoding))
+	}
+	if result.HasDefaultContentLanguage {
+		result.HasDefaultStoragePairs = true
+		result.DefaultStoragePairs.CreateMultipart = append(result.DefaultStoragePairs.CreateMultipart, WithContentLanguage(result.DefaultContentLanguage))
+		result.DefaultStoragePairs.QuerySignHTTPWrite = append(result.DefaultStoragePairs.QuerySignHTTPWrite, WithContentLanguage(result.DefaultContentLanguage))
+		result.DefaultStoragePairs.Write = append(result.DefaultStoragePairs.Write, WithContentLanguage(result.DefaultContentLanguage))
+	}
 	if result.HasDefaultContentType {
 		result.HasDefaultStoragePairs = true
+		result.DefaultStoragePairs.CreateMultipart = append(result.DefaultStoragePairs.CreateMultipart, WithContentType(result.DefaultContentType))
 		result.DefaultStoragePairs.QuerySignHTTPWrite = append(result.DefaultStoragePairs.QuerySignHTTPWrite, WithContentType(result.DefaultContentType))
 		result.DefaultStoragePairs.Write = append(result.DefaultStoragePairs.Write, WithContentType(result.DefaultContentType))
 	}
+	if result.HasDefaultExpires {
+		result.HasDefaultStoragePairs = true
+		result.DefaultStoragePairs.CreateMultipart = append(result.DefaultStoragePairs.CreateMultipart, WithExpires(result.DefaultExpires))
+		result.DefaultStoragePairs.QuerySignHTTPWrite = append(result.DefaultStoragePairs.QuerySignHTTPWrite, WithExpires(result.DefaultExpires))
+		result.DefaultStoragePairs.Write = append(result.DefaultStoragePairs.Write, WithExpires(result.DefaultExpires))
+	}
 	if result.HasDefaultIoCallback {
 		result.HasDefaultStoragePairs = true
 		result.DefaultStoragePairs.Read = append(result.DefaultStoragePairs.Read, WithIoCallback(result.DefaultIoCallback))
@@ -685,6 +1226,7 @@ func parsePairStorageNew(opts []Pair) (pairStorageNew, error) {
 	if result.HasDefaultStorageClass {
 		result.HasDefaultStoragePairs = true
 		result.DefaultStoragePairs.CreateDir = append(result.DefaultStoragePairs.CreateDir, WithStorageClass(result.DefaultStorageClass))
+		result.DefaultStoragePairs.CreateMultipart = append(result.DefaultStoragePairs.CreateMultipart, WithStorageClass(result.DefaultStorageClass))
 		result.DefaultStoragePairs.QuerySignHTTPWrite = append(result.DefaultStoragePairs.QuerySignHTTPWrite, WithStorageClass(result.DefaultStorageClass))
 		result.DefaultStoragePairs.Write = append(result.DefaultStoragePairs.Write, WithStorageClass(result.DefaultStorageClass))
 	}
@@ -726,6 +1268,10 @@ type pairStorageCompleteMultipart struct {
 	// Optional pairs
 	HasExceptedBucketOwner bool
 	ExceptedBucketOwner    string
+	HasIfMatch             bool
+	IfMatch                string
+	HasIfNoneMatch         bool
+	IfNoneMatch            string
 }
 
 func (s *Storage) parsePairStorageCompleteMultipart(opts []Pair) (pairStorageCompleteMultipart, error) {
@@ -740,6 +1286,18 @@ func (s *Storage) parsePairStorageCompleteMultipart(opts []Pair) (pairStorageCom
 			}
 			result.HasExceptedBucketOwner = true
 			result.ExceptedBucketOwner = v.Value.(string)
+		case "if_match":
+			if result.HasIfMatch {
+				continue
+			}
+			result.HasIfMatch = true
+			result.IfMatch = v.Value.(string)
+		case "if_none_match":
+			if result.HasIfNoneMatch {
+				continue
+			}
+			result.HasIfNoneMatch = true
+			result.IfNoneMatch = v.Value.(string)
 		default:
 			return pairStorageCompleteMultipart{}, services.PairUnsupportedError{Pair: v}
 		}
@@ -788,10 +1346,24 @@ type pairStorageCreateDir struct {
 	pairs []Pair
 	// Required pairs
 	// Optional pairs
+	HasACL                 bool
+	ACL                    string
 	HasExceptedBucketOwner bool
 	ExceptedBucketOwner    string
+	HasGrantFullControl    bool
+	GrantFullControl       string
+	HasGrantRead           bool
+	GrantRead              string
+	HasGrantReadAcp        bool
+	GrantReadAcp           string
+	HasGrantWriteAcp       bool
+	GrantWriteAcp          string
+	HasImplicitDir         bool
+	ImplicitDir            bool
 	HasStorageClass        bool
 	StorageClass           string
+	HasUserMetadata        bool
+	UserMetadata           map[string]string
 }
 
 func (s *Storage) parsePairStorageCreateDir(opts []Pair) (pairStorageCreateDir, error) {
@@ -800,18 +1372,60 @@ func (s *Storage) parsePairStorageCreateDir(opts []Pair) (pairStorageCreateDir,
 
 	for _, v := range opts {
 		switch v.Key {
+		case "acl":
+			if result.HasACL {
+				continue
+			}
+			result.HasACL = true
+			result.ACL = v.Value.(string)
 		case "excepted_bucket_owner":
 			if result.HasExceptedBucketOwner {
 				continue
 			}
 			result.HasExceptedBucketOwner = true
 			result.ExceptedBucketOwner = v.Value.(string)
+		case "grant_full_control":
+			if result.HasGrantFullControl {
+				continue
+			}
+			result.HasGrantFullControl = true
+			result.GrantFullControl = v.Value.(string)
+		case "grant_read":
+			if result.HasGrantRead {
+				continue
+			}
+			result.HasGrantRead = true
+			result.GrantRead = v.Value.(string)
+		case "grant_read_acp":
+			if result.HasGrantReadAcp {
+				continue
+			}
+			result.HasGrantReadAcp = true
+			result.GrantReadAcp = v.Value.(string)
+		case "grant_write_acp":
+			if result.HasGrantWriteAcp {
+				continue
+			}
+			result.HasGrantWriteAcp = true
+			result.GrantWriteAcp = v.Value.(string)
+		case "implicit_dir":
+			if result.HasImplicitDir {
+				continue
+			}
+			result.HasImplicitDir = true
+			result.ImplicitDir = v.Value.(bool)
 		case "storage_class":
 			if result.HasStorageClass {
 				continue
 			}
 			result.HasStorageClass = true
 			result.StorageClass = v.Value.(string)
+		case "user_metadata":
+			if result.HasUserMetadata {
+				continue
+			}
+			result.HasUserMetadata = true
+			result.UserMetadata = v.Value.(map[string]string)
 		default:
 			return pairStorageCreateDir{}, services.PairUnsupportedError{Pair: v}
 		}
@@ -844,8 +1458,36 @@ type pairStorageCreateMultipart struct {
 	pairs []Pair
 	// Required pairs
 	// Optional pairs
+	HasACL                                   bool
+	ACL                                      string
+	HasCacheControl                          bool
+	CacheControl                             string
+	HasContentDisposition                    bool
+	ContentDisposition                       string
+	HasContentEncoding                       bool
+	ContentEncoding                          string
+	HasContentLanguage                       bool
+	ContentLanguage                          string
+	HasContentType                           bool
+	ContentType                              string
 	HasExceptedBucketOwner                   bool
 	ExceptedBucketOwner                      string
+	HasExpires                               bool
+	Expires                                  time.Time
+	HasGrantFullControl                      bool
+	GrantFullControl                         string
+	HasGrantRead                             bool
+	GrantRead                                string
+	HasGrantReadAcp                          bool
+	GrantReadAcp                             string
+	HasGrantWriteAcp                         bool
+	GrantWriteAcp                            string
+	HasObjectLockLegalHold                   bool
+	ObjectLockLegalHold                      bool
+	HasObjectLockMode                        bool
+	ObjectLockMode                           string
+	HasObjectLockRetainUntilDate             bool
+	ObjectLockRetainUntilDate                time.Time
 	HasServerSideEncryption                  bool
 	ServerSideEncryption                     string
 	HasServerSideEncryptionAwsKmsKeyID       bool
@@ -858,6 +1500,12 @@ type pairStorageCreateMultipart struct {
 	ServerSideEncryptionCustomerAlgorithm    string
 	HasServerSideEncryptionCustomerKey       bool
 	ServerSideEncryptionCustomerKey          []byte
+	HasStorageClass                          bool
+	StorageClass                             string
+	HasTagging                               bool
+	Tagging                                  map[string]string
+	HasUserMetadata                          bool
+	UserMetadata                             map[string]string
 }
 
 func (s *Storage) parsePairStorageCreateMultipart(opts []Pair) (pairStorageCreateMultipart, error) {
@@ -866,12 +1514,96 @@ func (s *Storage) parsePairStorageCreateMultipart(opts []Pair) (pairStorageCreat
 
 	for _, v := range opts {
 		switch v.Key {
+		case "acl":
+			if result.HasACL {
+				continue
+			}
+			result.HasACL = true
+			result.ACL = v.Value.(string)
+		case "cache_control":
+			if result.HasCacheControl {
+				continue
+			}
+			result.HasCacheControl = true
+			result.CacheControl = v.Value.(string)
+		case "content_disposition":
+			if result.HasContentDisposition {
+				continue
+			}
+			result.HasContentDisposition = true
+			result.ContentDisposition = v.Value.(string)
+		case "content_encoding":
+			if result.HasContentEncoding {
+				continue
+			}
+			result.HasContentEncoding = true
+			result.ContentEncoding = v.Value.(string)
+		case "content_language":
+			if result.HasContentLanguage {
+				continue
+			}
+			result.HasContentLanguage = true
+			result.ContentLanguage = v.Value.(string)
+		case "content_type":
+			if result.HasContentType {
+				continue
+			}
+			result.HasContentType = true
+			result.ContentType = v.Value.(string)
 		case "excepted_bucket_owner":
 			if result.HasExceptedBucketOwner {
 				continue
 			}
 			result.HasExceptedBucketOwner = true
 			result.ExceptedBucketOwner = v.Value.(string)
+		case "expires":
+			if result.HasExpires {
+				continue
+			}
+			result.HasExpires = true
+			result.Expires = v.Value.(time.Time)
+		case "grant_full_control":
+			if result.HasGrantFullControl {
+				continue
+			}
+			result.HasGrantFullControl = true
+			result.GrantFullControl = v.Value.(string)
+		case "grant_read":
+			if result.HasGrantRead {
+				continue
+			}
+			result.HasGrantRead = true
+			result.GrantRead = v.Value.(string)
+		case "grant_read_acp":
+			if result.HasGrantReadAcp {
+				continue
+			}
+			result.HasGrantReadAcp = true
+			result.GrantReadAcp = v.Value.(string)
+		case "grant_write_acp":
+			if result.HasGrantWriteAcp {
+				continue
+			}
+			result.HasGrantWriteAcp = true
+			result.GrantWriteAcp = v.Value.(string)
+		case "object_lock_legal_hold":
+			if result.HasObjectLockLegalHold {
+				continue
+			}
+			result.HasObjectLockLegalHold = true
+			result.ObjectLockLegalHold = v.Value.(bool)
+		case "object_lock_mode":
+			if result.HasObjectLockMode {
+				continue
+			}
+			result.HasObjectLockMode = true
+			result.ObjectLockMode = v.Value.(string)
+		case "object_lock_retain_until_date":
+			if result.HasObjectLockRetainUntilDate {
+				continue
+			}
+			result.HasObjectLockRetainUntilDate = true
+			result.ObjectLockRetainUntilDate = v.Value.(time.Time)
 		case "server_side_encryption":
 			if result.HasServerSideEncryption {
 				continue
@@ -908,6 +1640,24 @@ func (s *Storage) parsePairStorageCreateMultipart(opts []Pair) (pairStorageCreat
 			}
 			result.HasServerSideEncryptionCustomerKey = true
 			result.ServerSideEncryptionCustomerKey = v.Value.([]byte)
+		case "storage_class":
+			if result.HasStorageClass {
+				continue
+			}
+			result.HasStorageClass = true
+			result.StorageClass = v.Value.(string)
+		case "tagging":
+			if result.HasTagging {
+				continue
+			}
+			result.HasTagging = true
+			result.Tagging = v.Value.(map[string]string)
+		case "user_metadata":
+			if result.HasUserMetadata {
+				continue
+			}
+			result.HasUserMetadata = true
+			result.UserMetadata = v.Value.(map[string]string)
 		default:
 			return pairStorageCreateMultipart{}, services.PairUnsupportedError{Pair: v}
 		}
@@ -920,12 +1670,14 @@ type pairStorageDelete struct {
 	pairs []Pair
 	// Required pairs
 	// Optional pairs
-	HasExceptedBucketOwner bool
-	ExceptedBucketOwner    string
-	HasMultipartID         bool
-	MultipartID            string
-	HasObjectMode          bool
-	ObjectMode             ObjectMode
+	HasBypassGovernanceRetention bool
+	BypassGovernanceRetention    bool
+	HasExceptedBucketOwner       bool
+	ExceptedBucketOwner          string
+	HasMultipartID               bool
+	MultipartID                  string
+	HasObjectMode                bool
+	ObjectMode                   ObjectMode
 }
 
 func (s *Storage) parsePairStorageDelete(opts []Pair) (pairStorageDelete, error) {
@@ -934,6 +1686,12 @@ func (s *Storage) parsePairStorageDelete(opts []Pair) (pairStorageDelete, error)
 
 	for _, v := range opts {
 		switch v.Key {
+		case "bypass_governance_retention":
+			if result.HasBypassGovernanceRetention {
+				continue
+			}
+			result.HasBypassGovernanceRetention = true
+			result.BypassGovernanceRetention = v.Value.(bool)
 		case "excepted_bucket_owner":
 			if result.HasExceptedBucketOwner {
 				continue
@@ -964,10 +1722,24 @@ type pairStorageList struct {
 	pairs []Pair
 	// Required pairs
 	// Optional pairs
+	HasConcurrency         bool
+	Concurrency            int
+	HasContinuationToken   bool
+	ContinuationToken      string
+	HasEncodingType        bool
+	EncodingType           string
 	HasExceptedBucketOwner bool
 	ExceptedBucketOwner    string
+	HasFetchOwner          bool
+	FetchOwner             bool
 	HasListMode            bool
 	ListMode               ListMode
+	HasMaxKeys             bool
+	MaxKeys                int32
+	HasResolveMode         bool
+	ResolveMode            bool
+	HasStartAfter          bool
+	StartAfter             string
 }
 
 func (s *Storage) parsePairStorageList(opts []Pair) (pairStorageList, error) {
@@ -976,18 +1748,60 @@ func (s *Storage) parsePairStorageList(opts []Pair) (pairStorageList, error) {
 
 	for _, v := range opts {
 		switch v.Key {
+		case "concurrency":
+			if result.HasConcurrency {
+				continue
+			}
+			result.HasConcurrency = true
+			result.Concurrency = v.Value.(int)
+		case "continuation_token":
+			if result.HasContinuationToken {
+				continue
+			}
+			result.HasContinuationToken = true
+			result.ContinuationToken = v.Value.(string)
+		case "encoding_type":
+			if result.HasEncodingType {
+				continue
+			}
+			result.HasEncodingType = true
+			result.EncodingType = v.Value.(string)
 		case "excepted_bucket_owner":
 			if result.HasExceptedBucketOwner {
 				continue
 			}
 			result.HasExceptedBucketOwner = true
 			result.ExceptedBucketOwner = v.Value.(string)
+		case "fetch_owner":
+			if result.HasFetchOwner {
+				continue
+			}
+			result.HasFetchOwner = true
+			result.FetchOwner = v.Value.(bool)
 		case "list_mode":
 			if result.HasListMode {
 				continue
 			}
 			result.HasListMode = true
 			result.ListMode = v.Value.(ListMode)
+		case "max_keys":
+			if result.HasMaxKeys {
+				continue
+			}
+			result.HasMaxKeys = true
+			result.MaxKeys = v.Value.(int32)
+		case "resolve_mode":
+			if result.HasResolveMode {
+				continue
+			}
+			result.HasResolveMode = true
+			result.ResolveMode = v.Value.(bool)
+		case "start_after":
+			if result.HasStartAfter {
+				continue
+			}
+			result.HasStartAfter = true
+			result.StartAfter = v.Value.(string)
 		default:
 			return pairStorageList{}, services.PairUnsupportedError{Pair: v}
 		}
@@ -1154,8 +1968,24 @@ type pairStorageQuerySignHTTPRead struct {
 	// Optional pairs
 	HasExceptedBucketOwner                   bool
 	ExceptedBucketOwner                      string
+	HasFollowLinks                           bool
+	FollowLinks                              bool
+	HasFollowLinksMaxDepth                   bool
+	FollowLinksMaxDepth                      int
 	HasOffset                                bool
 	Offset                                   int64
+	HasResponseCacheControl                  bool
+	ResponseCacheControl                     string
+	HasResponseContentDisposition            bool
+	ResponseContentDisposition               string
+	HasResponseContentEncoding               bool
+	ResponseContentEncoding                  string
+	HasResponseContentLanguage               bool
+	ResponseContentLanguage                  string
+	HasResponseContentType                   bool
+	ResponseContentType                      string
+	HasResponseExpires                       bool
+	ResponseExpires                          time.Time
 	HasServerSideEncryptionCustomerAlgorithm bool
 	ServerSideEncryptionCustomerAlgorithm    string
 	HasServerSideEncryptionCustomerKey       bool
@@ -1176,12 +2006,60 @@ func (s *Storage) parsePairStorageQuerySignHTTPRead(opts []Pair) (pairStorageQue
 			}
 			result.HasExceptedBucketOwner = true
 			result.ExceptedBucketOwner = v.Value.(string)
+		case "follow_links":
+			if result.HasFollowLinks {
+				continue
+			}
+			result.HasFollowLinks = true
+			result.FollowLinks = v.Value.(bool)
+		case "follow_links_max_depth":
+			if result.HasFollowLinksMaxDepth {
+				continue
+			}
+			result.HasFollowLinksMaxDepth = true
+			result.FollowLinksMaxDepth = v.Value.(int)
 		case "offset":
 			if result.HasOffset {
 				continue
 			}
 			result.HasOffset = true
 			result.Offset = v.Value.(int64)
+		case "response_cache_control":
+			if result.HasResponseCacheControl {
+				continue
+			}
+			result.HasResponseCacheControl = true
+			result.ResponseCacheControl = v.Value.(string)
+		case "response_content_disposition":
+			if result.HasResponseContentDisposition {
+				continue
+			}
+			result.HasResponseContentDisposition = true
+			result.ResponseContentDisposition = v.Value.(string)
+		case "response_content_encoding":
+			if result.HasResponseContentEncoding {
+				continue
+			}
+			result.HasResponseContentEncoding = true
+			result.ResponseContentEncoding = v.Value.(string)
+		case "response_content_language":
+			if result.HasResponseContentLanguage {
+				continue
+			}
+			result.HasResponseContentLanguage = true
+			result.ResponseContentLanguage = v.Value.(string)
+		case "response_content_type":
+			if result.HasResponseContentType {
+				continue
+			}
+			result.HasResponseContentType = true
+			result.ResponseContentType = v.Value.(string)
+		case "response_expires":
+			if result.HasResponseExpires {
+				continue
+			}
+			result.HasResponseExpires = true
+			result.ResponseExpires = v.Value.(time.Time)
 		case "server_side_encryption_customer_algorithm":
 			if result.HasServerSideEncryptionCustomerAlgorithm {
 				continue
@@ -1212,12 +2090,32 @@ type pairStorageQuerySignHTTPWrite struct {
 	pairs []Pair
 	// Required pairs
 	// Optional pairs
+	HasACL                                   bool
+	ACL                                      string
+	HasCacheControl                          bool
+	CacheControl                             string
+	HasContentDisposition                    bool
+	ContentDisposition                       string
+	HasContentEncoding                       bool
+	ContentEncoding                          string
+	HasContentLanguage                       bool
+	ContentLanguage                          string
 	HasContentMd5                            bool
 	ContentMd5                               string
 	HasContentType                           bool
 	ContentType                              string
 	HasExceptedBucketOwner                   bool
 	ExceptedBucketOwner                      string
+	HasExpires                               bool
+	Expires                                  time.Time
+	HasGrantFullControl                      bool
+	GrantFullControl                         string
+	HasGrantRead                             bool
+	GrantRead                                string
+	HasGrantReadAcp                          bool
+	GrantReadAcp                             string
+	HasGrantWriteAcp                         bool
+	GrantWriteAcp                            string
 	HasServerSideEncryption                  bool
 	ServerSideEncryption                     string
 	HasServerSideEncryptionAwsKmsKeyID       bool
@@ -1232,6 +2130,8 @@ type pairStorageQuerySignHTTPWrite struct {
 	ServerSideEncryptionCustomerKey          []byte
 	HasStorageClass                          bool
 	StorageClass                             string
+	HasUserMetadata                          bool
+	UserMetadata                             map[string]string
 }
 
 func (s *Storage) parsePairStorageQuerySignHTTPWrite(opts []Pair) (pairStorageQuerySignHTTPWrite, error) {
@@ -1240,6 +2140,36 @@ func (s *Storage) parsePairStorageQuerySignHTTPWrite(opts []Pair) (pairStorageQu
 
 	for _, v := range opts {
 		switch v.Key {
+		case "acl":
+			if result.HasACL {
+				continue
+			}
+			result.HasACL = true
+			result.ACL = v.Value.(string)
+		case "cache_control":
+			if result.HasCacheControl {
+				continue
+			}
+			result.HasCacheControl = true
+			result.CacheControl = v.Value.(string)
+		case "content_disposition":
+			if result.HasContentDisposition {
+				continue
+			}
+			result.HasContentDisposition = true
+			result.ContentDisposition = v.Value.(string)
+		case "content_encoding":
+			if result.HasContentEncoding {
+				continue
+			}
+			result.HasContentEncoding = true
+			result.ContentEncoding = v.Value.(string)
+		case "content_language":
+			if result.HasContentLanguage {
+				continue
+			}
+			result.HasContentLanguage = true
+			result.ContentLanguage = v.Value.(string)
 		case "content_md5":
 			if result.HasContentMd5 {
 				continue
@@ -1258,6 +2188,36 @@ func (s *Storage) parsePairStorageQuerySignHTTPWrite(opts []Pair) (pairStorageQu
 			}
 			result.HasExceptedBucketOwner = true
 			result.ExceptedBucketOwner = v.Value.(string)
+		case "expires":
+			if result.HasExpires {
+				continue
+			}
+			result.HasExpires = true
+			result.Expires = v.Value.(time.Time)
+		case "grant_full_control":
+			if result.HasGrantFullControl {
+				continue
+			}
+			result.HasGrantFullControl = true
+			result.GrantFullControl = v.Value.(string)
+		case "grant_read":
+			if result.HasGrantRead {
+				continue
+			}
+			result.HasGrantRead = true
+			result.GrantRead = v.Value.(string)
+		case "grant_read_acp":
+			if result.HasGrantReadAcp {
+				continue
+			}
+			result.HasGrantReadAcp = true
+			result.GrantReadAcp = v.Value.(string)
+		case "grant_write_acp":
+			if result.HasGrantWriteAcp {
+				continue
+			}
+			result.HasGrantWriteAcp = true
+			result.GrantWriteAcp = v.Value.(string)
 		case "server_side_encryption":
 			if result.HasServerSideEncryption {
 				continue
@@ -1300,6 +2260,12 @@ func (s *Storage) parsePairStorageQuerySignHTTPWrite(opts []Pair) (pairStorageQu
 			}
 			result.HasStorageClass = true
 			result.StorageClass = v.Value.(string)
+		case "user_metadata":
+			if result.HasUserMetadata {
+				continue
+			}
+			result.HasUserMetadata = true
+			result.UserMetadata = v.Value.(map[string]string)
 		default:
 			return pairStorageQuerySignHTTPWrite{}, services.PairUnsupportedError{Pair: v}
 		}
@@ -1334,16 +2300,42 @@ type pairStorageRead struct {
 	// Optional pairs
 	HasExceptedBucketOwner                   bool
 	ExceptedBucketOwner                      string
+	HasFollowLinks                           bool
+	FollowLinks                              bool
+	HasFollowLinksMaxDepth                   bool
+	FollowLinksMaxDepth                      int
+	HasIfMatch                               bool
+	IfMatch                                  string
+	HasIfModifiedSince                       bool
+	IfModifiedSince                          time.Time
+	HasIfNoneMatch                           bool
+	IfNoneMatch                              string
+	HasIfUnmodifiedSince                     bool
+	IfUnmodifiedSince                        time.Time
 	HasIoCallback                            bool
 	IoCallback                               func([]byte)
 	HasOffset                                bool
 	Offset                                   int64
+	HasResponseCacheControl                  bool
+	ResponseCacheControl                     string
+	HasResponseContentDisposition            bool
+	ResponseContentDisposition               string
+	HasResponseContentEncoding               bool
+	ResponseContentEncoding                  string
+	HasResponseContentLanguage               bool
+	ResponseContentLanguage                  string
+	HasResponseContentType                   bool
+	ResponseContentType                      string
+	HasResponseExpires                       bool
+	ResponseExpires                          time.Time
 	HasServerSideEncryptionCustomerAlgorithm bool
 	ServerSideEncryptionCustomerAlgorithm    string
 	HasServerSideEncryptionCustomerKey       bool
 	ServerSideEncryptionCustomerKey          []byte
 	HasSize                                  bool
 	Size                                     int64
+	HasUserMetadataCallback                  bool
+	UserMetadataCallback                     func(map[string]string)
 }
 
 func (s *Storage) parsePairStorageRead(opts []Pair) (pairStorageRead, error) {
@@ -1358,6 +2350,42 @@ func (s *Storage) parsePairStorageRead(opts []Pair) (pairStorageRead, error) {
 			}
 			result.HasExceptedBucketOwner = true
 			result.ExceptedBucketOwner = v.Value.(string)
+		case "follow_links":
+			if result.HasFollowLinks {
+				continue
+			}
+			result.HasFollowLinks = true
+			result.FollowLinks = v.Value.(bool)
+		case "follow_links_max_depth":
+			if result.HasFollowLinksMaxDepth {
+				continue
+			}
+			result.HasFollowLinksMaxDepth = true
+			result.FollowLinksMaxDepth = v.Value.(int)
+		case "if_match":
+			if result.HasIfMatch {
+				continue
+			}
+			result.HasIfMatch = true
+			result.IfMatch = v.Value.(string)
+		case "if_modified_since":
+			if result.HasIfModifiedSince {
+				continue
+			}
+			result.HasIfModifiedSince = true
+			result.IfModifiedSince = v.Value.(time.Time)
+		case "if_none_match":
+			if result.HasIfNoneMatch {
+				continue
+			}
+			result.HasIfNoneMatch = true
+			result.IfNoneMatch = v.Value.(string)
+		case "if_unmodified_since":
+			if result.HasIfUnmodifiedSince {
+				continue
+			}
+			result.HasIfUnmodifiedSince = true
+			result.IfUnmodifiedSince = v.Value.(time.Time)
 		case "io_callback":
 			if result.HasIoCallback {
 				continue
@@ -1370,6 +2398,42 @@ func (s *Storage) parsePairStorageRead(opts []Pair) (pairStorageRead, error) {
 			}
 			result.HasOffset = true
 			result.Offset = v.Value.(int64)
+		case "response_cache_control":
+			if result.HasResponseCacheControl {
+				continue
+			}
+			result.HasResponseCacheControl = true
+			result.ResponseCacheControl = v.Value.(string)
+		case "response_content_disposition":
+			if result.HasResponseContentDisposition {
+				continue
+			}
+			result.HasResponseContentDisposition = true
+			result.ResponseContentDisposition = v.Value.(string)
+		case "response_content_encoding":
+			if result.HasResponseContentEncoding {
+				continue
+			}
+			result.HasResponseContentEncoding = true
+			result.ResponseContentEncoding = v.Value.(string)
+		case "response_content_language":
+			if result.HasResponseContentLanguage {
+				continue
+			}
+			result.HasResponseContentLanguage = true
+			result.ResponseContentLanguage = v.Value.(string)
+		case "response_content_type":
+			if result.HasResponseContentType {
+				continue
+			}
+			result.HasResponseContentType = true
+			result.ResponseContentType = v.Value.(string)
+		case "response_expires":
+			if result.HasResponseExpires {
+				continue
+			}
+			result.HasResponseExpires = true
+			result.ResponseExpires = v.Value.(time.Time)
 		case "server_side_encryption_customer_algorithm":
 			if result.HasServerSideEncryptionCustomerAlgorithm {
 				continue
@@ -1388,6 +2452,12 @@ func (s *Storage) parsePairStorageRead(opts []Pair) (pairStorageRead, error) {
 			}
 			result.HasSize = true
 			result.Size = v.Value.(int64)
+		case "user_metadata_callback":
+			if result.HasUserMetadataCallback {
+				continue
+			}
+			result.HasUserMetadataCallback = true
+			result.UserMetadataCallback = v.Value.(func(map[string]string))
 		default:
 			return pairStorageRead{}, services.PairUnsupportedError{Pair: v}
 		}
@@ -1402,6 +2472,18 @@ type pairStorageStat struct {
 	// Optional pairs
 	HasExceptedBucketOwner                   bool
 	ExceptedBucketOwner                      string
+	HasFollowLinks                           bool
+	FollowLinks                              bool
+	HasFollowLinksMaxDepth                   bool
+	FollowLinksMaxDepth                      int
+	HasIfMatch                               bool
+	IfMatch                                  string
+	HasIfModifiedSince                       bool
+	IfModifiedSince                          time.Time
+	HasIfNoneMatch                           bool
+	IfNoneMatch                              string
+	HasIfUnmodifiedSince                     bool
+	IfUnmodifiedSince                        time.Time
 	HasMultipartID                           bool
 	MultipartID                              string
 	HasObjectMode                            bool
@@ -1424,6 +2506,42 @@ func (s *Storage) parsePairStorageStat(opts []Pair) (pairStorageStat, error) {
 			}
 			result.HasExceptedBucketOwner = true
 			result.ExceptedBucketOwner = v.Value.(string)
+		case "follow_links":
+			if result.HasFollowLinks {
+				continue
+			}
+			result.HasFollowLinks = true
+			result.FollowLinks = v.Value.(bool)
+		case "follow_links_max_depth":
+			if result.HasFollowLinksMaxDepth {
+				continue
+			}
+			result.HasFollowLinksMaxDepth = true
+			result.FollowLinksMaxDepth = v.Value.(int)
+		case "if_match":
+			if result.HasIfMatch {
+				continue
+			}
+			result.HasIfMatch = true
+			result.IfMatch = v.Value.(string)
+		case "if_modified_since":
+			if result.HasIfModifiedSince {
+				continue
+			}
+			result.HasIfModifiedSince = true
+			result.IfModifiedSince = v.Value.(time.Time)
+		case "if_none_match":
+			if result.HasIfNoneMatch {
+				continue
+			}
+			result.HasIfNoneMatch = true
+			result.IfNoneMatch = v.Value.(string)
+		case "if_unmodified_since":
+			if result.HasIfUnmodifiedSince {
+				continue
+			}
+			result.HasIfUnmodifiedSince = true
+			result.IfUnmodifiedSince = v.Value.(time.Time)
 		case "multipart_id":
 			if result.HasMultipartID {
 				continue
@@ -1460,14 +2578,44 @@ type pairStorageWrite struct {
 	pairs []Pair
 	// Required pairs
 	// Optional pairs
+	HasACL                                   bool
+	ACL                                      string
+	HasCacheControl                          bool
+	CacheControl                             string
+	HasContentDisposition                    bool
+	ContentDisposition                       string
+	HasContentEncoding                       bool
+	ContentEncoding                          string
+	HasContentLanguage                       bool
+	ContentLanguage                          string
 	HasContentMd5                            bool
 	ContentMd5                               string
 	HasContentType                           bool
 	ContentType                              string
 	HasExceptedBucketOwner                   bool
 	ExceptedBucketOwner                      string
+	HasExpires                               bool
+	Expires                                  time.Time
+	HasGrantFullControl                      bool
+	GrantFullControl                         string
+	HasGrantRead                             bool
+	GrantRead                                string
+	HasGrantReadAcp                          bool
+	GrantReadAcp                             string
+	HasGrantWriteAcp                         bool
+	GrantWriteAcp                            string
+	HasIfMatch                               bool
+	IfMatch                                  string
+	HasIfNoneMatch                           bool
+	IfNoneMatch                              string
 	HasIoCallback                            bool
 	IoCallback                               func([]byte)
+	HasObjectLockLegalHold                   bool
+	ObjectLockLegalHold                      bool
+	HasObjectLockMode                        bool
+	ObjectLockMode                           string
+	HasObjectLockRetainUntilDate             bool
+	ObjectLockRetainUntilDate                time.Time
 	HasServerSideEncryption                  bool
 	ServerSideEncryption                     string
 	HasServerSideEncryptionAwsKmsKeyID       bool
@@ -1482,6 +2630,10 @@ type pairStorageWrite struct {
 	ServerSideEncryptionCustomerKey          []byte
 	HasStorageClass                          bool
 	StorageClass                             string
+	HasTagging                               bool
+	Tagging                                  map[string]string
+	HasUserMetadata                          bool
+	UserMetadata                             map[string]string
 }
 
 func (s *Storage) parsePairStorageWrite(opts []Pair) (pairStorageWrite, error) {
@@ -1490,6 +2642,36 @@ func (s *Storage) parsePairStorageWrite(opts []Pair) (pairStorageWrite, error) {
 
 	for _, v := range opts {
 		switch v.Key {
+		case "acl":
+			if result.HasACL {
+				continue
+			}
+			result.HasACL = true
+			result.ACL = v.Value.(string)
+		case "cache_control":
+			if result.HasCacheControl {
+				continue
+			}
+			result.HasCacheControl = true
+			result.CacheControl = v.Value.(string)
+		case "content_disposition":
+			if result.HasContentDisposition {
+				continue
+			}
+			result.HasContentDisposition = true
+			result.ContentDisposition = v.Value.(string)
+		case "content_encoding":
+			if result.HasContentEncoding {
+				continue
+			}
+			result.HasContentEncoding = true
+			result.ContentEncoding = v.Value.(string)
+		case "content_language":
+			if result.HasContentLanguage {
+				continue
+			}
+			result.HasContentLanguage = true
+			result.ContentLanguage = v.Value.(string)
 		case "content_md5":
 			if result.HasContentMd5 {
 				continue
@@ -1508,12 +2690,72 @@ func (s *Storage) parsePairStorageWrite(opts []Pair) (pairStorageWrite, error) {
 			}
 			result.HasExceptedBucketOwner = true
 			result.ExceptedBucketOwner = v.Value.(string)
+		case "expires":
+			if result.HasExpires {
+				continue
+			}
+			result.HasExpires = true
+			result.Expires = v.Value.(time.Time)
+		case "grant_full_control":
+			if result.HasGrantFullControl {
+				continue
+			}
+			result.HasGrantFullControl = true
+			result.GrantFullControl = v.Value.(string)
+		case "grant_read":
+			if result.HasGrantRead {
+				continue
+			}
+			result.HasGrantRead = true
+			result.GrantRead = v.Value.(string)
+		case "grant_read_acp":
+			if result.HasGrantReadAcp {
+				continue
+			}
+			result.HasGrantReadAcp = true
+			result.GrantReadAcp = v.Value.(string)
+		case "grant_write_acp":
+			if result.HasGrantWriteAcp {
+				continue
+			}
+			result.HasGrantWriteAcp = true
+			result.GrantWriteAcp = v.Value.(string)
+		case "if_match":
+			if result.HasIfMatch {
+				continue
+			}
+			result.HasIfMatch = true
+			result.IfMatch = v.Value.(string)
+		case "if_none_match":
+			if result.HasIfNoneMatch {
+				continue
+			}
+			result.HasIfNoneMatch = true
+			result.IfNoneMatch = v.Value.(string)
 		case "io_callback":
 			if result.HasIoCallback {
 				continue
 			}
 			result.HasIoCallback = true
 			result.IoCallback = v.Value.(func([]byte))
+		case "object_lock_legal_hold":
+			if result.HasObjectLockLegalHold {
+				continue
+			}
+			result.HasObjectLockLegalHold = true
+			result.ObjectLockLegalHold = v.Value.(bool)
+		case "object_lock_mode":
+			if result.HasObjectLockMode {
+				continue
+			}
+			result.HasObjectLockMode = true
+			result.ObjectLockMode = v.Value.(string)
+		case "object_lock_retain_until_date":
+			if result.HasObjectLockRetainUntilDate {
+				continue
+			}
+			result.HasObjectLockRetainUntilDate = true
+			result.ObjectLockRetainUntilDate = v.Value.(time.Time)
 		case "server_side_encryption":
 			if result.HasServerSideEncryption {
 				continue
@@ -1556,6 +2798,18 @@ func (s *Storage) parsePairStorageWrite(opts []Pair) (pairStorageWrite, error) {
 			}
 			result.HasStorageClass = true
 			result.StorageClass = v.Value.(string)
+		case "tagging":
+			if result.HasTagging {
+				continue
+			}
+			result.HasTagging = true
+			result.Tagging = v.Value.(map[string]string)
+		case "user_metadata":
+			if result.HasUserMetadata {
+				continue
+			}
+			result.HasUserMetadata = true
+			result.UserMetadata = v.Value.(map[string]string)
 		default:
 			return pairStorageWrite{}, services.PairUnsupportedError{Pair: v}
 		}
@@ -3,11 +3,12 @@ module github.com/beyondstorage/go-service-s3/v2
 go 1.14
 
 require (
-	github.com/aws/aws-sdk-go-v2 v1.9.2
-	github.com/aws/aws-sdk-go-v2/config v1.8.3
-	github.com/aws/aws-sdk-go-v2/credentials v1.4.3
-	github.com/aws/aws-sdk-go-v2/service/s3 v1.16.1
-	github.com/aws/smithy-go v1.8.0
+	github.com/aws/aws-sdk-go-v2 v1.16.2
+	github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream v1.4.1
+	github.com/aws/aws-sdk-go-v2/config v1.15.3
+	github.com/aws/aws-sdk-go-v2/credentials v1.11.2
+	github.com/aws/aws-sdk-go-v2/service/s3 v1.26.3
+	github.com/aws/smithy-go v1.11.2
 	github.com/beyondstorage/go-endpoint v1.1.0
 	github.com/beyondstorage/go-integration-test/v4 v4.6.0
 	github.com/beyondstorage/go-storage/v4 v4.8.0
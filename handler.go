@@ -0,0 +1,364 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+
+	"github.com/beyondstorage/go-storage/v4/services"
+	. "github.com/beyondstorage/go-storage/v4/types"
+)
+
+// pairStorageHandler is the parsed struct for Handler.
+type pairStorageHandler struct {
+	pairs []Pair
+	// Optional pairs
+	HasExceptedBucketOwner bool
+	ExceptedBucketOwner    string
+	HasHTTPRedirect        bool
+	HTTPRedirect           time.Duration
+}
+
+func (s *Storage) parsePairStorageHandler(opts []Pair) (pairStorageHandler, error) {
+	result := pairStorageHandler{pairs: opts}
+
+	for _, v := range opts {
+		switch v.Key {
+		case "excepted_bucket_owner":
+			if result.HasExceptedBucketOwner {
+				continue
+			}
+			result.HasExceptedBucketOwner = true
+			result.ExceptedBucketOwner = v.Value.(string)
+		case "http_redirect":
+			if result.HasHTTPRedirect {
+				continue
+			}
+			result.HasHTTPRedirect = true
+			result.HTTPRedirect = v.Value.(time.Duration)
+		default:
+			return pairStorageHandler{}, services.PairUnsupportedError{Pair: v}
+		}
+	}
+
+	return result, nil
+}
+
+// Handler will return a http.Handler which serves objects under the dir path, only GET and HEAD are allowed.
+//
+// The URL path of requests is relative to path. Range, If-None-Match and If-Modified-Since of requests will
+// be sent with GetObject, and ETag, Last-Modified and Content-Type of objects will be sent back. Requests to
+// dirs ending with "/" will be served with a listing of the dir.
+//
+// Use WithHTTPRedirect to redirect clients to a signed URL expiring after the duration instead of proxying
+// the content, dirs will still be listed by the handler.
+func (s *Storage) Handler(path string, pairs ...Pair) (h http.Handler, err error) {
+	defer func() {
+		err = s.formatError("handler", err, path)
+	}()
+
+	opt, err := s.parsePairStorageHandler(pairs)
+	if err != nil {
+		return
+	}
+	return &storageHandler{
+		s:      s,
+		prefix: formatDirPath(strings.ReplaceAll(path, "\\", "/")),
+		opt:    opt,
+	}, nil
+}
+
+type storageHandler struct {
+	s      *Storage
+	prefix string
+	opt    pairStorageHandler
+}
+
+func (h *storageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	upath := r.URL.Path
+	if !strings.HasPrefix(upath, "/") {
+		upath = "/" + upath
+	}
+	// path.Clean will remove all ".." elements, so that requests could not escape the prefix.
+	name := strings.TrimPrefix(path.Clean(upath), "/")
+	if name == "" || strings.HasSuffix(upath, "/") {
+		h.serveDir(w, r, name)
+		return
+	}
+	if h.opt.HasHTTPRedirect {
+		h.serveRedirect(w, r, name)
+		return
+	}
+	h.serveFile(w, r, name)
+}
+
+func (h *storageHandler) serveRedirect(w http.ResponseWriter, r *http.Request, name string) {
+	var opt pairStorageQuerySignHTTPRead
+	if h.opt.HasExceptedBucketOwner {
+		opt.pairs = append(opt.pairs, WithExceptedBucketOwner(h.opt.ExceptedBucketOwner))
+	}
+	req, err := h.s.querySignHTTPRead(r.Context(), h.prefix+name, h.opt.HTTPRedirect, opt)
+	if err != nil {
+		h.serveError(w, err)
+		return
+	}
+	http.Redirect(w, r, req.URL.String(), http.StatusTemporaryRedirect)
+}
+
+func (h *storageHandler) serveFile(w http.ResponseWriter, r *http.Request, name string) {
+	p := h.prefix + name
+
+	var opt pairStorageRead
+	if v := r.Header.Get("If-None-Match"); v != "" {
+		opt.HasIfNoneMatch, opt.IfNoneMatch = true, v
+	} else if t, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil {
+		// If-Modified-Since must be ignored if If-None-Match is present, see RFC 7232 section 3.3.
+		opt.HasIfModifiedSince, opt.IfModifiedSince = true, t
+	}
+	if h.opt.HasExceptedBucketOwner {
+		opt.HasExceptedBucketOwner, opt.ExceptedBucketOwner = true, h.opt.ExceptedBucketOwner
+	}
+
+	var (
+		header objectHeader
+		body   io.ReadCloser
+		err    error
+	)
+	if r.Method == http.MethodHead {
+		header, err = h.headObject(r.Context(), p, opt)
+	} else {
+		header, body, err = h.getObject(r.Context(), p, opt, r.Header)
+	}
+	if err != nil {
+		// Redirect to the dir like http.FileServer if the object doesn't exist but the dir does.
+		if errors.Is(formatError(err), services.ErrObjectNotExist) {
+			if _, exist, serr := h.s.statImplicitDir(r.Context(), p, h.s.getAbsPath(p)+"/", pairStorageStat{
+				HasExceptedBucketOwner: h.opt.HasExceptedBucketOwner,
+				ExceptedBucketOwner:    h.opt.ExceptedBucketOwner,
+			}); serr == nil && exist {
+				redirectDir(w, r)
+				return
+			}
+		}
+		h.serveError(w, err)
+		return
+	}
+
+	header.write(w.Header())
+	if body == nil {
+		return
+	}
+	defer body.Close()
+
+	if header.contentRange != "" {
+		w.WriteHeader(http.StatusPartialContent)
+	}
+	_, _ = io.Copy(w, body)
+}
+
+// getObject will send GetObject with Range of the request, multiple ranges are not supported by S3 and will
+// be ignored. Range will also be ignored if If-Range is present, as we don't know the ETag of the object yet.
+func (h *storageHandler) getObject(ctx context.Context, p string, opt pairStorageRead, reqHeader http.Header) (header objectHeader, body io.ReadCloser, err error) {
+	input, err := h.s.formatGetObjectInput(p, opt)
+	if err != nil {
+		return
+	}
+	if v := reqHeader.Get("Range"); strings.HasPrefix(v, "bytes=") && !strings.Contains(v, ",") && reqHeader.Get("If-Range") == "" {
+		input.Range = aws.String(v)
+	}
+
+	output, err := h.s.service.GetObject(ctx, input)
+	if err != nil {
+		return
+	}
+	header = objectHeader{
+		etag:               aws.ToString(output.ETag),
+		lastModified:       aws.ToTime(output.LastModified),
+		contentLength:      output.ContentLength,
+		contentRange:       aws.ToString(output.ContentRange),
+		contentType:        aws.ToString(output.ContentType),
+		contentEncoding:    aws.ToString(output.ContentEncoding),
+		contentDisposition: aws.ToString(output.ContentDisposition),
+		cacheControl:       aws.ToString(output.CacheControl),
+	}
+	return header, output.Body, nil
+}
+
+// headObject will send HeadObject without Range, as Range must be ignored for HEAD, see RFC 7233 section 3.1.
+func (h *storageHandler) headObject(ctx context.Context, p string, opt pairStorageRead) (header objectHeader, err error) {
+	input, err := h.s.formatHeadObjectInput(p, pairStorageStat{
+		HasExceptedBucketOwner: opt.HasExceptedBucketOwner,
+		ExceptedBucketOwner:    opt.ExceptedBucketOwner,
+	})
+	if err != nil {
+		return
+	}
+	if opt.HasIfNoneMatch {
+		input.IfNoneMatch = &opt.IfNoneMatch
+	}
+	if opt.HasIfModifiedSince {
+		input.IfModifiedSince = &opt.IfModifiedSince
+	}
+
+	output, err := h.s.service.HeadObject(ctx, input)
+	if err != nil {
+		return
+	}
+	return objectHeader{
+		etag:               aws.ToString(output.ETag),
+		lastModified:       aws.ToTime(output.LastModified),
+		contentLength:      output.ContentLength,
+		contentType:        aws.ToString(output.ContentType),
+		contentEncoding:    aws.ToString(output.ContentEncoding),
+		contentDisposition: aws.ToString(output.ContentDisposition),
+		cacheControl:       aws.ToString(output.CacheControl),
+	}, nil
+}
+
+func (h *storageHandler) serveDir(w http.ResponseWriter, r *http.Request, name string) {
+	dir := h.prefix
+	if name != "" {
+		dir += name + "/"
+	}
+
+	it, err := h.s.list(r.Context(), dir, pairStorageList{
+		HasListMode:            true,
+		ListMode:               ListModeDir,
+		HasMaxKeys:             true,
+		MaxKeys:                listMaxKeysMaximum,
+		HasExceptedBucketOwner: h.opt.HasExceptedBucketOwner,
+		ExceptedBucketOwner:    h.opt.ExceptedBucketOwner,
+	})
+	if err != nil {
+		h.serveError(w, err)
+		return
+	}
+
+	var (
+		entries []string
+		// exist means there is any object under the dir, including the dir marker.
+		exist = name == ""
+	)
+	for {
+		o, err := it.Next()
+		if errors.Is(err, IterateDone) {
+			break
+		}
+		if err != nil {
+			h.serveError(w, err)
+			return
+		}
+
+		exist = true
+		// The marker of the dir itself will be listed under its prefix.
+		if o.Path == dir {
+			continue
+		}
+		entries = append(entries, strings.TrimPrefix(o.Path, dir))
+	}
+	if !exist {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "<!doctype html>\n<meta name=\"viewport\" content=\"width=device-width\">\n<pre>\n")
+	for _, v := range entries {
+		// Names could contain ":", which will be treated as a scheme without "./".
+		u := url.URL{Path: "./" + v}
+		_, _ = fmt.Fprintf(w, "<a href=\"%s\">%s</a>\n", html.EscapeString(u.String()), html.EscapeString(v))
+	}
+	_, _ = fmt.Fprintf(w, "</pre>\n")
+}
+
+// serveError will write the status of err, conditional requests which are not satisfied are not errors.
+func (h *storageHandler) serveError(w http.ResponseWriter, err error) {
+	var re *awshttp.ResponseError
+	if errors.As(err, &re) && re.Response != nil {
+		switch code := re.HTTPStatusCode(); code {
+		case http.StatusNotModified:
+			for _, k := range []string{"ETag", "Last-Modified", "Cache-Control"} {
+				if v := re.Response.Header.Get(k); v != "" {
+					w.Header().Set(k, v)
+				}
+			}
+			w.WriteHeader(code)
+			return
+		case http.StatusPreconditionFailed, http.StatusRequestedRangeNotSatisfiable:
+			http.Error(w, http.StatusText(code), code)
+			return
+		}
+	}
+
+	err = formatError(err)
+	switch {
+	case errors.Is(err, services.ErrObjectNotExist):
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	case errors.Is(err, services.ErrPermissionDenied):
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	default:
+		// Don't expose the details of errors to clients.
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// redirectDir will redirect the request to the dir with a trailing "/".
+func redirectDir(w http.ResponseWriter, r *http.Request) {
+	u := path.Base(r.URL.Path) + "/"
+	if r.URL.RawQuery != "" {
+		u += "?" + r.URL.RawQuery
+	}
+	http.Redirect(w, r, u, http.StatusMovedPermanently)
+}
+
+// objectHeader is the headers of an object which will be sent back to clients.
+type objectHeader struct {
+	etag               string
+	lastModified       time.Time
+	contentLength      int64
+	contentRange       string
+	contentType        string
+	contentEncoding    string
+	contentDisposition string
+	cacheControl       string
+}
+
+func (o objectHeader) write(h http.Header) {
+	for k, v := range map[string]string{
+		"ETag":                o.etag,
+		"Content-Range":       o.contentRange,
+		"Content-Type":        o.contentType,
+		"Content-Encoding":    o.contentEncoding,
+		"Content-Disposition": o.contentDisposition,
+		"Cache-Control":       o.cacheControl,
+	} {
+		if v != "" {
+			h.Set(k, v)
+		}
+	}
+	if !o.lastModified.IsZero() {
+		h.Set("Last-Modified", o.lastModified.UTC().Format(http.TimeFormat))
+	}
+	h.Set("Content-Length", strconv.FormatInt(o.contentLength, 10))
+	h.Set("Accept-Ranges", "bytes")
+}
@@ -2,6 +2,7 @@ package s3
 
 import (
 	"strconv"
+	"strings"
 )
 
 type objectPageStatus struct {
@@ -11,6 +12,11 @@ type objectPageStatus struct {
 
 	// Only used for object
 	continuationToken string
+	startAfter        string
+	encodingType      string
+	fetchOwner        bool
+	resolveMode       bool
+	concurrency       int
 
 	// Only used for part object
 	keyMarker      string
@@ -32,12 +38,25 @@ func (i objectPageStatus) getServiceContinuationToken() *string {
 }
 
 func (i *objectPageStatus) ContinuationToken() string {
-	if i.uploadIdMarker != "" {
-		return i.continuationToken + "/" + i.uploadIdMarker
+	if i.keyMarker != "" || i.uploadIdMarker != "" {
+		return i.keyMarker + "/" + i.uploadIdMarker
 	}
 	return i.continuationToken
 }
 
+// setPartContinuationToken will parse the token returned by ContinuationToken for part listing.
+//
+// The key could contain "/" but the upload id couldn't, so we split the token with the last "/".
+func (i *objectPageStatus) setPartContinuationToken(token string) {
+	idx := strings.LastIndex(token, "/")
+	if idx == -1 {
+		i.keyMarker = token
+		return
+	}
+	i.keyMarker = token[:idx]
+	i.uploadIdMarker = token[idx+1:]
+}
+
 type storagePageStatus struct {
 	limit    int
 	offset   int
@@ -0,0 +1,84 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+// followLinksMaxDepthDefault is the default max number of links to follow in a link chain.
+const followLinksMaxDepthDefault = 8
+
+// isRelativeLinkTarget will check whether the link target is relative to the link's dir.
+func isRelativeLinkTarget(target string) bool {
+	return target == "." || target == ".." ||
+		strings.HasPrefix(target, "./") || strings.HasPrefix(target, "../")
+}
+
+// resolveLinkTarget will resolve the target stored in link object rp to an absolute key.
+//
+// Targets created by create_link are absolute keys which already contain the work dir, while relative
+// targets starting with "./" or "../" are relative to the dir of the link object.
+func resolveLinkTarget(rp string, target string) string {
+	if isRelativeLinkTarget(target) {
+		return strings.TrimPrefix(path.Join(path.Dir(rp), target), "/")
+	}
+	return strings.TrimPrefix(target, "/")
+}
+
+// resolveLinkKey will return the key of the final target of rp if followLinks is true, otherwise rp is returned.
+func (s *Storage) resolveLinkKey(ctx context.Context, rp string, followLinks bool, hasMaxDepth bool, maxDepth int, expectedBucketOwner string) (string, error) {
+	if !followLinks {
+		return rp, nil
+	}
+	if !hasMaxDepth {
+		maxDepth = followLinksMaxDepthDefault
+	}
+	return s.followLinks(ctx, rp, maxDepth, expectedBucketOwner)
+}
+
+// followLinks will follow the link chain started at rp, and return the key of the final target.
+//
+// Links are detected with HeadObject without conditional and SSE-C pairs, which only apply to the final target.
+func (s *Storage) followLinks(ctx context.Context, rp string, maxDepth int, expectedBucketOwner string) (string, error) {
+	visited := map[string]struct{}{rp: {}}
+
+	for depth := 0; ; depth++ {
+		input := &s3.HeadObjectInput{
+			Bucket: aws.String(s.name),
+			Key:    aws.String(rp),
+		}
+		if expectedBucketOwner != "" {
+			input.ExpectedBucketOwner = &expectedBucketOwner
+		}
+		output, err := s.service.HeadObject(ctx, input)
+		if err != nil {
+			// Objects encrypted with SSE-C can't be read without the key, but links are never encrypted.
+			var ae smithy.APIError
+			if errors.As(err, &ae) && ae.ErrorCode() == "BadRequest" {
+				return rp, nil
+			}
+			return "", err
+		}
+
+		target, ok := output.Metadata[metadataLinkTargetHeader]
+		if !ok {
+			return rp, nil
+		}
+		if depth >= maxDepth {
+			return "", fmt.Errorf("follow links from %s: %w", rp, ErrLinkDepthExceeded)
+		}
+
+		rp = resolveLinkTarget(rp, target)
+		if _, ok := visited[rp]; ok {
+			return "", fmt.Errorf("follow links to %s: %w", rp, ErrLinkCycle)
+		}
+		visited[rp] = struct{}{}
+	}
+}
@@ -0,0 +1,224 @@
+package s3
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/beyondstorage/go-storage/v4/services"
+	. "github.com/beyondstorage/go-storage/v4/types"
+)
+
+// All available Object Lock modes are listed here.
+const (
+	ObjectLockModeGovernance = s3types.ObjectLockModeGovernance
+	ObjectLockModeCompliance = s3types.ObjectLockModeCompliance
+)
+
+// ObjectRetention is the Object Lock retention of an object.
+type ObjectRetention struct {
+	// Mode could be ObjectLockModeGovernance or ObjectLockModeCompliance.
+	Mode s3types.ObjectLockMode
+	// RetainUntilDate is the date on which the retention will expire.
+	RetainUntilDate time.Time
+}
+
+func formatLegalHoldStatus(on bool) s3types.ObjectLockLegalHoldStatus {
+	if on {
+		return s3types.ObjectLockLegalHoldStatusOn
+	}
+	return s3types.ObjectLockLegalHoldStatusOff
+}
+
+// pairStorageObjectLock is the parsed struct for Object Lock operations.
+type pairStorageObjectLock struct {
+	pairs []Pair
+	// Optional pairs
+	HasBypassGovernanceRetention bool
+	BypassGovernanceRetention    bool
+	HasExceptedBucketOwner       bool
+	ExceptedBucketOwner          string
+}
+
+func (s *Storage) parsePairStorageObjectLock(opts []Pair) (pairStorageObjectLock, error) {
+	result := pairStorageObjectLock{pairs: opts}
+
+	for _, v := range opts {
+		switch v.Key {
+		case "bypass_governance_retention":
+			if result.HasBypassGovernanceRetention {
+				continue
+			}
+			result.HasBypassGovernanceRetention = true
+			result.BypassGovernanceRetention = v.Value.(bool)
+		case "excepted_bucket_owner":
+			if result.HasExceptedBucketOwner {
+				continue
+			}
+			result.HasExceptedBucketOwner = true
+			result.ExceptedBucketOwner = v.Value.(string)
+		default:
+			return pairStorageObjectLock{}, services.PairUnsupportedError{Pair: v}
+		}
+	}
+
+	return result, nil
+}
+
+// GetObjectRetention will return the Object Lock retention of the object.
+func (s *Storage) GetObjectRetention(path string, pairs ...Pair) (retention *ObjectRetention, err error) {
+	ctx := context.Background()
+	return s.GetObjectRetentionWithContext(ctx, path, pairs...)
+}
+
+// GetObjectRetentionWithContext will return the Object Lock retention of the object.
+func (s *Storage) GetObjectRetentionWithContext(ctx context.Context, path string, pairs ...Pair) (retention *ObjectRetention, err error) {
+	defer func() {
+		err = s.formatError("get_object_retention", err, path)
+	}()
+
+	opt, err := s.parsePairStorageObjectLock(pairs)
+	if err != nil {
+		return
+	}
+	return s.getObjectRetention(ctx, strings.ReplaceAll(path, "\\", "/"), opt)
+}
+
+// PutObjectRetention will set the Object Lock retention of the object.
+//
+// Use WithBypassGovernanceRetention to shorten or remove a governance-mode retention.
+func (s *Storage) PutObjectRetention(path string, retention *ObjectRetention, pairs ...Pair) (err error) {
+	ctx := context.Background()
+	return s.PutObjectRetentionWithContext(ctx, path, retention, pairs...)
+}
+
+// PutObjectRetentionWithContext will set the Object Lock retention of the object.
+//
+// Use WithBypassGovernanceRetention to shorten or remove a governance-mode retention.
+func (s *Storage) PutObjectRetentionWithContext(ctx context.Context, path string, retention *ObjectRetention, pairs ...Pair) (err error) {
+	defer func() {
+		err = s.formatError("put_object_retention", err, path)
+	}()
+
+	opt, err := s.parsePairStorageObjectLock(pairs)
+	if err != nil {
+		return
+	}
+	return s.putObjectRetention(ctx, strings.ReplaceAll(path, "\\", "/"), retention, opt)
+}
+
+// GetObjectLegalHold will return whether a legal hold is applied to the object.
+func (s *Storage) GetObjectLegalHold(path string, pairs ...Pair) (on bool, err error) {
+	ctx := context.Background()
+	return s.GetObjectLegalHoldWithContext(ctx, path, pairs...)
+}
+
+// GetObjectLegalHoldWithContext will return whether a legal hold is applied to the object.
+func (s *Storage) GetObjectLegalHoldWithContext(ctx context.Context, path string, pairs ...Pair) (on bool, err error) {
+	defer func() {
+		err = s.formatError("get_object_legal_hold", err, path)
+	}()
+
+	opt, err := s.parsePairStorageObjectLock(pairs)
+	if err != nil {
+		return
+	}
+	return s.getObjectLegalHold(ctx, strings.ReplaceAll(path, "\\", "/"), opt)
+}
+
+// PutObjectLegalHold will apply or remove the legal hold of the object.
+func (s *Storage) PutObjectLegalHold(path string, on bool, pairs ...Pair) (err error) {
+	ctx := context.Background()
+	return s.PutObjectLegalHoldWithContext(ctx, path, on, pairs...)
+}
+
+// PutObjectLegalHoldWithContext will apply or remove the legal hold of the object.
+func (s *Storage) PutObjectLegalHoldWithContext(ctx context.Context, path string, on bool, pairs ...Pair) (err error) {
+	defer func() {
+		err = s.formatError("put_object_legal_hold", err, path)
+	}()
+
+	opt, err := s.parsePairStorageObjectLock(pairs)
+	if err != nil {
+		return
+	}
+	return s.putObjectLegalHold(ctx, strings.ReplaceAll(path, "\\", "/"), on, opt)
+}
+
+func (s *Storage) getObjectRetention(ctx context.Context, path string, opt pairStorageObjectLock) (retention *ObjectRetention, err error) {
+	input := &s3.GetObjectRetentionInput{
+		Bucket: aws.String(s.name),
+		Key:    aws.String(s.getAbsPath(path)),
+	}
+	if opt.HasExceptedBucketOwner {
+		input.ExpectedBucketOwner = &opt.ExceptedBucketOwner
+	}
+	output, err := s.service.GetObjectRetention(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	retention = &ObjectRetention{}
+	if output.Retention != nil {
+		// ObjectLockRetentionMode and ObjectLockMode share the same values.
+		retention.Mode = s3types.ObjectLockMode(output.Retention.Mode)
+		retention.RetainUntilDate = aws.ToTime(output.Retention.RetainUntilDate)
+	}
+	return retention, nil
+}
+
+func (s *Storage) putObjectRetention(ctx context.Context, path string, retention *ObjectRetention, opt pairStorageObjectLock) (err error) {
+	input := &s3.PutObjectRetentionInput{
+		Bucket: aws.String(s.name),
+		Key:    aws.String(s.getAbsPath(path)),
+		Retention: &s3types.ObjectLockRetention{
+			Mode:            s3types.ObjectLockRetentionMode(retention.Mode),
+			RetainUntilDate: aws.Time(retention.RetainUntilDate),
+		},
+	}
+	if opt.HasExceptedBucketOwner {
+		input.ExpectedBucketOwner = &opt.ExceptedBucketOwner
+	}
+	if opt.HasBypassGovernanceRetention {
+		input.BypassGovernanceRetention = opt.BypassGovernanceRetention
+	}
+	_, err = s.service.PutObjectRetention(ctx, input)
+	return err
+}
+
+func (s *Storage) getObjectLegalHold(ctx context.Context, path string, opt pairStorageObjectLock) (on bool, err error) {
+	input := &s3.GetObjectLegalHoldInput{
+		Bucket: aws.String(s.name),
+		Key:    aws.String(s.getAbsPath(path)),
+	}
+	if opt.HasExceptedBucketOwner {
+		input.ExpectedBucketOwner = &opt.ExceptedBucketOwner
+	}
+	output, err := s.service.GetObjectLegalHold(ctx, input)
+	if err != nil {
+		return false, err
+	}
+	if output.LegalHold == nil {
+		return false, nil
+	}
+	return output.LegalHold.Status == s3types.ObjectLockLegalHoldStatusOn, nil
+}
+
+func (s *Storage) putObjectLegalHold(ctx context.Context, path string, on bool, opt pairStorageObjectLock) (err error) {
+	input := &s3.PutObjectLegalHoldInput{
+		Bucket: aws.String(s.name),
+		Key:    aws.String(s.getAbsPath(path)),
+		LegalHold: &s3types.ObjectLockLegalHold{
+			Status: formatLegalHoldStatus(on),
+		},
+	}
+	if opt.HasExceptedBucketOwner {
+		input.ExpectedBucketOwner = &opt.ExceptedBucketOwner
+	}
+	_, err = s.service.PutObjectLegalHold(ctx, input)
+	return err
+}
@@ -0,0 +1,435 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/beyondstorage/go-storage/v4/services"
+	. "github.com/beyondstorage/go-storage/v4/types"
+)
+
+// defaultMirrorConcurrency is the default number of objects mirrored concurrently.
+const defaultMirrorConcurrency = 4
+
+// MirrorSummary is the summary of Mirror.
+type MirrorSummary struct {
+	// Copied is the count of objects copied with server-side copy.
+	Copied int64
+	// Streamed is the count of objects streamed through the client, as they can't be read by the destination.
+	Streamed int64
+	// Skipped is the count of objects unchanged in the destination.
+	Skipped int64
+	// Bytes is the total size of objects copied and streamed.
+	Bytes int64
+	// Errors contains the errors of objects failed to mirror.
+	Errors []error
+}
+
+// pairStorageMirror is the parsed struct for Mirror.
+type pairStorageMirror struct {
+	pairs []Pair
+	// Optional pairs
+	HasConcurrency          bool
+	Concurrency             int
+	HasPreserveMetadata     bool
+	PreserveMetadata        bool
+	HasPreserveStorageClass bool
+	PreserveStorageClass    bool
+	HasPreserveTagging      bool
+	PreserveTagging         bool
+}
+
+func (s *Storage) parsePairStorageMirror(opts []Pair) (pairStorageMirror, error) {
+	result := pairStorageMirror{pairs: opts}
+
+	for _, v := range opts {
+		switch v.Key {
+		case "concurrency":
+			if result.HasConcurrency {
+				continue
+			}
+			result.HasConcurrency = true
+			result.Concurrency = v.Value.(int)
+		case "preserve_metadata":
+			if result.HasPreserveMetadata {
+				continue
+			}
+			result.HasPreserveMetadata = true
+			result.PreserveMetadata = v.Value.(bool)
+		case "preserve_storage_class":
+			if result.HasPreserveStorageClass {
+				continue
+			}
+			result.HasPreserveStorageClass = true
+			result.PreserveStorageClass = v.Value.(bool)
+		case "preserve_tagging":
+			if result.HasPreserveTagging {
+				continue
+			}
+			result.HasPreserveTagging = true
+			result.PreserveTagging = v.Value.(bool)
+		default:
+			return pairStorageMirror{}, services.PairUnsupportedError{Pair: v}
+		}
+	}
+
+	return result, nil
+}
+
+// Mirror will copy objects under the dir path to the dir dstPath of dst, which could be another bucket.
+//
+// Objects will be copied with server-side copy by the credentials of dst, and streamed through the client
+// if the source can't be read by dst. Objects with the same size and ETag in dst will be skipped, note that
+// the ETag of a multipart object depends on its part size, so it could be copied again.
+//
+// Use WithPreserveMetadata, WithPreserveTagging and WithPreserveStorageClass to keep the metadata, tags and
+// storage class of objects, Content-Type is always kept. Objects will be mirrored concurrently, use
+// WithConcurrency to control the concurrency, 4 by default. Failures of single objects will be collected
+// in the summary, err will only be returned if the objects can't be listed or ctx is canceled.
+func (s *Storage) Mirror(path string, dst *Storage, dstPath string, pairs ...Pair) (summary *MirrorSummary, err error) {
+	ctx := context.Background()
+	return s.MirrorWithContext(ctx, path, dst, dstPath, pairs...)
+}
+
+// MirrorWithContext will copy objects under the dir path to the dir dstPath of dst, which could be another bucket.
+//
+// Objects will be copied with server-side copy by the credentials of dst, and streamed through the client
+// if the source can't be read by dst. Objects with the same size and ETag in dst will be skipped, note that
+// the ETag of a multipart object depends on its part size, so it could be copied again.
+//
+// Use WithPreserveMetadata, WithPreserveTagging and WithPreserveStorageClass to keep the metadata, tags and
+// storage class of objects, Content-Type is always kept. Objects will be mirrored concurrently, use
+// WithConcurrency to control the concurrency, 4 by default. Failures of single objects will be collected
+// in the summary, err will only be returned if the objects can't be listed or ctx is canceled, ctx.Err()
+// will be returned as is once ctx is canceled.
+func (s *Storage) MirrorWithContext(ctx context.Context, path string, dst *Storage, dstPath string, pairs ...Pair) (summary *MirrorSummary, err error) {
+	defer func() {
+		// Keep ctx.Err() as is like Walk, so that it could be matched by errors.Is.
+		if err != nil && err == ctx.Err() {
+			return
+		}
+		err = s.formatError("mirror", err, path)
+	}()
+
+	opt, err := s.parsePairStorageMirror(pairs)
+	if err != nil {
+		return
+	}
+	return s.mirror(ctx, strings.ReplaceAll(path, "\\", "/"), dst, strings.ReplaceAll(dstPath, "\\", "/"), opt)
+}
+
+func (s *Storage) mirror(ctx context.Context, path string, dst *Storage, dstPath string, opt pairStorageMirror) (summary *MirrorSummary, err error) {
+	path, dstPath = formatDirPath(path), formatDirPath(dstPath)
+
+	srcFiles, err := s.listSyncFiles(ctx, path, "")
+	if err != nil {
+		return
+	}
+	dstFiles, err := dst.listSyncFiles(ctx, dstPath, "")
+	if err != nil {
+		return
+	}
+
+	concurrency := defaultMirrorConcurrency
+	if opt.HasConcurrency && opt.Concurrency > 0 {
+		concurrency = opt.Concurrency
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+		ch = make(chan string)
+	)
+	summary = &MirrorSummary{}
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for p := range ch {
+				f := srcFiles[p]
+				streamed, err := s.mirrorObject(ctx, path+p, dst, dstPath+p, f, opt)
+
+				mu.Lock()
+				switch {
+				case err != nil:
+					summary.Errors = append(summary.Errors, s.formatError("mirror", err, path+p))
+				case streamed:
+					summary.Streamed++
+					summary.Bytes += f.size
+				default:
+					summary.Copied++
+					summary.Bytes += f.size
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+produce:
+	for p, f := range srcFiles {
+		if df, ok := dstFiles[p]; ok && df.size == f.size && df.etag == f.etag {
+			summary.Skipped++
+			continue
+		}
+		select {
+		case ch <- p:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break produce
+		}
+	}
+	close(ch)
+	wg.Wait()
+
+	return summary, err
+}
+
+// mirrorObject will copy the object with server-side copy, and fall back to streaming if access is denied.
+func (s *Storage) mirrorObject(ctx context.Context, path string, dst *Storage, dstPath string, f syncFile, opt pairStorageMirror) (streamed bool, err error) {
+	if f.size <= copySizeMaximum {
+		err = s.copyObjectTo(ctx, path, dst, dstPath, f, opt)
+	} else {
+		err = s.multipartCopyObjectTo(ctx, path, dst, dstPath, f, opt)
+	}
+	if err == nil || !errors.Is(formatError(err), services.ErrPermissionDenied) {
+		return false, err
+	}
+
+	if f.size <= writeSizeMaximum {
+		err = s.streamObjectTo(ctx, path, dst, dstPath, f, opt)
+	} else {
+		err = s.multipartStreamObjectTo(ctx, path, dst, dstPath, f, opt)
+	}
+	return true, err
+}
+
+func (s *Storage) copyObjectTo(ctx context.Context, path string, dst *Storage, dstPath string, f syncFile, opt pairStorageMirror) (err error) {
+	input := &s3.CopyObjectInput{
+		Bucket:     aws.String(dst.name),
+		Key:        aws.String(dst.getAbsPath(dstPath)),
+		CopySource: aws.String(formatCopySource(s.name, s.getAbsPath(path))),
+		// Make sure the object is not changed since list.
+		CopySourceIfMatch: aws.String(`"` + f.etag + `"`),
+		MetadataDirective: s3types.MetadataDirectiveReplace,
+		TaggingDirective:  s3types.TaggingDirectiveReplace,
+	}
+	if opt.PreserveMetadata {
+		input.MetadataDirective = s3types.MetadataDirectiveCopy
+	} else {
+		// REPLACE drops every header of the source, so we have to carry over the
+		// Content-Type ourselves or the copy falls back to binary/octet-stream.
+		m, err := s.mirrorMetadata(ctx, path, f, pairStorageMirror{})
+		if err != nil {
+			return err
+		}
+		input.ContentType = m.head.ContentType
+	}
+	if opt.PreserveTagging {
+		input.TaggingDirective = s3types.TaggingDirectiveCopy
+	}
+	if opt.PreserveStorageClass {
+		input.StorageClass = s3types.StorageClass(f.storageClass)
+	}
+	_, err = dst.service.CopyObject(ctx, input)
+	return err
+}
+
+func (s *Storage) multipartCopyObjectTo(ctx context.Context, path string, dst *Storage, dstPath string, f syncFile, opt pairStorageMirror) (err error) {
+	// Multipart upload can't copy metadata and tags from the source, so we have to read them first.
+	m, err := s.mirrorMetadata(ctx, path, f, opt)
+	if err != nil {
+		return
+	}
+	o, err := dst.createMultipart(ctx, dstPath, m.createMultipartPairs())
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			dst.abortMultipart(ctx, o)
+		}
+	}()
+
+	partSize := calculatePartSize(f.size, copyPartSize)
+	var parts []*Part
+	for offset, index := int64(0), 0; offset < f.size; offset, index = offset+partSize, index+1 {
+		end := offset + partSize - 1
+		if end >= f.size {
+			end = f.size - 1
+		}
+
+		output, err := dst.service.UploadPartCopy(ctx, &s3.UploadPartCopyInput{
+			Bucket:            aws.String(dst.name),
+			Key:               aws.String(o.ID),
+			UploadId:          aws.String(o.MustGetMultipartID()),
+			PartNumber:        int32(index + 1),
+			CopySource:        aws.String(formatCopySource(s.name, s.getAbsPath(path))),
+			CopySourceRange:   aws.String(fmt.Sprintf("bytes=%d-%d", offset, end)),
+			CopySourceIfMatch: aws.String(`"` + f.etag + `"`),
+		})
+		if err != nil {
+			return err
+		}
+		parts = append(parts, &Part{
+			Index: index,
+			Size:  end - offset + 1,
+			ETag:  aws.ToString(output.CopyPartResult.ETag),
+		})
+	}
+	return dst.completeMultipart(ctx, o, parts, pairStorageCompleteMultipart{})
+}
+
+func (s *Storage) streamObjectTo(ctx context.Context, path string, dst *Storage, dstPath string, f syncFile, opt pairStorageMirror) (err error) {
+	m, err := s.mirrorMetadata(ctx, path, f, opt)
+	if err != nil {
+		return
+	}
+	return s.pipeObject(ctx, path, f, 0, f.size, func(r io.Reader) error {
+		_, err := dst.write(ctx, dstPath, r, f.size, m.writePairs())
+		return err
+	})
+}
+
+func (s *Storage) multipartStreamObjectTo(ctx context.Context, path string, dst *Storage, dstPath string, f syncFile, opt pairStorageMirror) (err error) {
+	m, err := s.mirrorMetadata(ctx, path, f, opt)
+	if err != nil {
+		return
+	}
+	o, err := dst.createMultipart(ctx, dstPath, m.createMultipartPairs())
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			dst.abortMultipart(ctx, o)
+		}
+	}()
+
+	partSize := calculatePartSize(f.size, copyPartSize)
+	var parts []*Part
+	for offset, index := int64(0), 0; offset < f.size; offset, index = offset+partSize, index+1 {
+		n := partSize
+		if offset+n > f.size {
+			n = f.size - offset
+		}
+
+		err = s.pipeObject(ctx, path, f, offset, n, func(r io.Reader) error {
+			_, part, err := dst.writeMultipart(ctx, o, r, n, index, pairStorageWriteMultipart{})
+			if err != nil {
+				return err
+			}
+			parts = append(parts, part)
+			return nil
+		})
+		if err != nil {
+			return
+		}
+	}
+	return dst.completeMultipart(ctx, o, parts, pairStorageCompleteMultipart{})
+}
+
+// pipeObject will read the range of the object in a new goroutine, and pass the content to fn.
+func (s *Storage) pipeObject(ctx context.Context, path string, f syncFile, offset, size int64, fn func(r io.Reader) error) error {
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := s.read(ctx, path, pw, pairStorageRead{
+			HasOffset: true,
+			Offset:    offset,
+			HasSize:   true,
+			Size:      size,
+			// Make sure the object is not changed since list.
+			HasIfMatch: true,
+			IfMatch:    `"` + f.etag + `"`,
+		})
+		pw.CloseWithError(err)
+	}()
+
+	err := fn(pr)
+	// Unblock the reading goroutine if fn returns before reading all content.
+	pr.CloseWithError(err)
+	return err
+}
+
+// mirrorMeta is the metadata of the source object to be preserved, which is used while CopyObject
+// can't be used to copy the metadata.
+//
+// Content-Type is always carried over, other headers and user metadata only with preserve_metadata.
+type mirrorMeta struct {
+	head         *s3.HeadObjectOutput
+	metadata     bool
+	tags         map[string]string
+	storageClass string
+}
+
+func (s *Storage) mirrorMetadata(ctx context.Context, path string, f syncFile, opt pairStorageMirror) (m mirrorMeta, err error) {
+	m.head, err = s.service.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.name),
+		Key:    aws.String(s.getAbsPath(path)),
+		// Make sure the object is not changed since list.
+		IfMatch: aws.String(`"` + f.etag + `"`),
+	})
+	if err != nil {
+		return
+	}
+	m.metadata = opt.PreserveMetadata
+	if opt.PreserveTagging {
+		m.tags, err = s.getObjectTagging(ctx, path, pairStorageObjectTagging{})
+		if err != nil {
+			return
+		}
+	}
+	if opt.PreserveStorageClass {
+		m.storageClass = f.storageClass
+	}
+	return m, nil
+}
+
+func (m mirrorMeta) writePairs() (opt pairStorageWrite) {
+	h := m.head
+	opt.HasContentType, opt.ContentType = h.ContentType != nil, aws.ToString(h.ContentType)
+	if m.metadata {
+		opt.HasUserMetadata, opt.UserMetadata = len(h.Metadata) > 0, h.Metadata
+		opt.HasCacheControl, opt.CacheControl = h.CacheControl != nil, aws.ToString(h.CacheControl)
+		opt.HasContentDisposition, opt.ContentDisposition = h.ContentDisposition != nil, aws.ToString(h.ContentDisposition)
+		opt.HasContentEncoding, opt.ContentEncoding = h.ContentEncoding != nil, aws.ToString(h.ContentEncoding)
+		opt.HasContentLanguage, opt.ContentLanguage = h.ContentLanguage != nil, aws.ToString(h.ContentLanguage)
+		opt.HasExpires, opt.Expires = h.Expires != nil, aws.ToTime(h.Expires)
+	}
+	opt.HasTagging, opt.Tagging = len(m.tags) > 0, m.tags
+	opt.HasStorageClass, opt.StorageClass = m.storageClass != "", m.storageClass
+	return opt
+}
+
+func (m mirrorMeta) createMultipartPairs() (opt pairStorageCreateMultipart) {
+	w := m.writePairs()
+	return pairStorageCreateMultipart{
+		HasUserMetadata:       w.HasUserMetadata,
+		UserMetadata:          w.UserMetadata,
+		HasContentType:        w.HasContentType,
+		ContentType:           w.ContentType,
+		HasCacheControl:       w.HasCacheControl,
+		CacheControl:          w.CacheControl,
+		HasContentDisposition: w.HasContentDisposition,
+		ContentDisposition:    w.ContentDisposition,
+		HasContentEncoding:    w.HasContentEncoding,
+		ContentEncoding:       w.ContentEncoding,
+		HasContentLanguage:    w.HasContentLanguage,
+		ContentLanguage:       w.ContentLanguage,
+		HasExpires:            w.HasExpires,
+		Expires:               w.Expires,
+		HasTagging:            w.HasTagging,
+		Tagging:               w.Tagging,
+		HasStorageClass:       w.HasStorageClass,
+		StorageClass:          w.StorageClass,
+	}
+}
@@ -0,0 +1,34 @@
+package s3
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/beyondstorage/go-storage/v4/types"
+)
+
+// QuerySignHTTPStat will return a presigned *http.Request for HeadObject.
+//
+// QuerySignHTTPStat accepts the same pairs as Stat.
+func (s *Storage) QuerySignHTTPStat(path string, expire time.Duration, pairs ...Pair) (req *http.Request, err error) {
+	ctx := context.Background()
+	return s.QuerySignHTTPStatWithContext(ctx, path, expire, pairs...)
+}
+
+// QuerySignHTTPStatWithContext will return a presigned *http.Request for HeadObject.
+//
+// QuerySignHTTPStatWithContext accepts the same pairs as Stat.
+func (s *Storage) QuerySignHTTPStatWithContext(ctx context.Context, path string, expire time.Duration, pairs ...Pair) (req *http.Request, err error) {
+	defer func() {
+		err = s.formatError("query_sign_http_stat", err, path)
+	}()
+
+	pairs = append(pairs, s.defaultPairs.Stat...)
+	opt, err := s.parsePairStorageStat(pairs)
+	if err != nil {
+		return
+	}
+	return s.querySignHTTPStat(ctx, strings.ReplaceAll(path, "\\", "/"), expire, opt)
+}
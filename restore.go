@@ -0,0 +1,159 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+
+	"github.com/beyondstorage/go-storage/v4/services"
+	. "github.com/beyondstorage/go-storage/v4/types"
+)
+
+// All available restore tiers are listed here.
+const (
+	RestoreTierExpedited = s3types.TierExpedited
+	RestoreTierStandard  = s3types.TierStandard
+	RestoreTierBulk      = s3types.TierBulk
+)
+
+// pairStorageRestoreObject is the parsed struct for RestoreObject.
+type pairStorageRestoreObject struct {
+	pairs []Pair
+	// Optional pairs
+	HasExceptedBucketOwner bool
+	ExceptedBucketOwner    string
+	HasRestoreDays         bool
+	RestoreDays            int32
+	HasRestoreTier         bool
+	RestoreTier            string
+}
+
+func (s *Storage) parsePairStorageRestoreObject(opts []Pair) (pairStorageRestoreObject, error) {
+	result := pairStorageRestoreObject{pairs: opts}
+
+	for _, v := range opts {
+		switch v.Key {
+		case "excepted_bucket_owner":
+			if result.HasExceptedBucketOwner {
+				continue
+			}
+			result.HasExceptedBucketOwner = true
+			result.ExceptedBucketOwner = v.Value.(string)
+		case "restore_days":
+			if result.HasRestoreDays {
+				continue
+			}
+			result.HasRestoreDays = true
+			result.RestoreDays = v.Value.(int32)
+		case "restore_tier":
+			if result.HasRestoreTier {
+				continue
+			}
+			result.HasRestoreTier = true
+			result.RestoreTier = v.Value.(string)
+		default:
+			return pairStorageRestoreObject{}, services.PairUnsupportedError{Pair: v}
+		}
+	}
+
+	return result, nil
+}
+
+// RestoreObject will start restoring a temporary copy of an archived object.
+//
+// Restore is asynchronous, use Stat to check RestoreOngoing and RestoreExpiryDate of the object.
+// ErrRestoreInProgress will be returned if the object is being restored, and ErrObjectNotArchived
+// will be returned if the object is not archived.
+func (s *Storage) RestoreObject(path string, pairs ...Pair) (err error) {
+	ctx := context.Background()
+	return s.RestoreObjectWithContext(ctx, path, pairs...)
+}
+
+// RestoreObjectWithContext will start restoring a temporary copy of an archived object.
+//
+// Restore is asynchronous, use Stat to check RestoreOngoing and RestoreExpiryDate of the object.
+// ErrRestoreInProgress will be returned if the object is being restored, and ErrObjectNotArchived
+// will be returned if the object is not archived.
+func (s *Storage) RestoreObjectWithContext(ctx context.Context, path string, pairs ...Pair) (err error) {
+	defer func() {
+		err = s.formatError("restore_object", err, path)
+	}()
+
+	opt, err := s.parsePairStorageRestoreObject(pairs)
+	if err != nil {
+		return
+	}
+	return s.restoreObject(ctx, strings.ReplaceAll(path, "\\", "/"), opt)
+}
+
+func (s *Storage) restoreObject(ctx context.Context, path string, opt pairStorageRestoreObject) (err error) {
+	req := &s3types.RestoreRequest{}
+	if opt.HasRestoreDays {
+		req.Days = opt.RestoreDays
+	}
+	if opt.HasRestoreTier {
+		req.GlacierJobParameters = &s3types.GlacierJobParameters{
+			Tier: s3types.Tier(opt.RestoreTier),
+		}
+	}
+
+	input := &s3.RestoreObjectInput{
+		Bucket:         aws.String(s.name),
+		Key:            aws.String(s.getAbsPath(path)),
+		RestoreRequest: req,
+	}
+	if opt.HasExceptedBucketOwner {
+		input.ExpectedBucketOwner = &opt.ExceptedBucketOwner
+	}
+	_, err = s.service.RestoreObject(ctx, input)
+	// S3 returns InvalidObjectState for both reading an archived object and restoring an object which is
+	// not archived, so it has to be mapped here instead of formatError.
+	var e smithy.APIError
+	if errors.As(err, &e) && e.ErrorCode() == "InvalidObjectState" {
+		return fmt.Errorf("%w: %v", ErrObjectNotArchived, err)
+	}
+	return err
+}
+
+// parseRestore will parse the value of x-amz-restore header, which looks like:
+//
+//	ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"
+//
+// expiry-date is only returned after the restore is completed.
+func parseRestore(v string) (ongoing bool, expiryDate time.Time, err error) {
+	// expiry-date contains comma, so we can't split the fields by comma directly.
+	for v = strings.TrimSpace(v); v != ""; v = strings.TrimLeft(v, ", ") {
+		idx := strings.Index(v, `="`)
+		if idx == -1 {
+			break
+		}
+		key := v[:idx]
+		v = v[idx+2:]
+
+		idx = strings.IndexByte(v, '"')
+		if idx == -1 {
+			return false, time.Time{}, fmt.Errorf("parse restore %q: unterminated value", v)
+		}
+		value := v[:idx]
+		v = v[idx+1:]
+
+		switch key {
+		case "ongoing-request":
+			ongoing = value == "true"
+		case "expiry-date":
+			expiryDate, err = time.Parse(http.TimeFormat, value)
+			if err != nil {
+				return false, time.Time{}, fmt.Errorf("parse restore expiry date %q: %w", value, err)
+			}
+		}
+	}
+	return ongoing, expiryDate, nil
+}
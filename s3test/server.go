@@ -0,0 +1,915 @@
+/*
+Package s3test provides an in-memory S3 compatible server for tests.
+
+The server only implements the operations used by this service: PutObject, GetObject, HeadObject, DeleteObject,
+ListObjects and ListObjectsV2, the multipart upload APIs, CreateBucket, DeleteBucket, HeadBucket and ListBuckets.
+Signatures are not verified, and other operations will return NotImplemented.
+
+Only path style requests are supported, so the storage must be created with WithForcePathStyle:
+
+	srv := s3test.NewServer("bucket")
+	defer srv.Close()
+
+	store, err := s3.NewStorager(
+		ps.WithCredential("hmac:access_key:secret_key"),
+		ps.WithEndpoint(srv.Endpoint()),
+		ps.WithLocation("us-east-1"),
+		ps.WithName("bucket"),
+		s3.WithForcePathStyle(),
+	)
+*/
+package s3test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultMaxKeys is the default and maximum number of keys, uploads or parts returned in one page.
+	defaultMaxKeys = 1000
+	// minPartSize is the minimum size of parts except the last one.
+	minPartSize = 5 * 1024 * 1024
+	// maxPartNumber is the maximum part number of a multipart upload.
+	maxPartNumber = 10000
+
+	timeFormat = "2006-01-02T15:04:05.000Z"
+)
+
+// storedHeaders are the headers of PutObject and CreateMultipartUpload which will be returned by GetObject and HeadObject.
+var storedHeaders = []string{
+	"Cache-Control",
+	"Content-Disposition",
+	"Content-Encoding",
+	"Content-Language",
+	"Content-Type",
+	"Expires",
+	"X-Amz-Storage-Class",
+	"X-Amz-Website-Redirect-Location",
+}
+
+// unsupportedSubresources are the sub-resources of operations which are not implemented.
+var unsupportedSubresources = []string{
+	"accelerate", "acl", "analytics", "cors", "delete", "encryption", "intelligent-tiering", "inventory",
+	"legal-hold", "lifecycle", "location", "logging", "metrics", "notification", "object-lock", "ownershipControls",
+	"policy", "publicAccessBlock", "replication", "requestPayment", "restore", "retention", "select", "tagging",
+	"torrent", "versioning", "versions", "website",
+}
+
+// Server is an in-memory S3 compatible server.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	buckets  map[string]*bucket
+	uploadID int
+}
+
+type bucket struct {
+	created time.Time
+	objects map[string]*object
+	uploads map[string]*upload
+}
+
+type object struct {
+	data    []byte
+	etag    string
+	modTime time.Time
+	header  http.Header
+}
+
+type upload struct {
+	key       string
+	id        string
+	initiated time.Time
+	header    http.Header
+	parts     map[int]*object
+}
+
+// NewServer will start a server with the buckets created.
+func NewServer(buckets ...string) *Server {
+	s := &Server{buckets: make(map[string]*bucket)}
+	for _, name := range buckets {
+		s.buckets[name] = newBucket()
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Endpoint will return the endpoint of the server in the form of go-storage endpoint pair.
+func (s *Server) Endpoint() string {
+	return "http:" + strings.TrimPrefix(s.URL, "http://")
+}
+
+func newBucket() *bucket {
+	return &bucket{
+		created: time.Now(),
+		objects: make(map[string]*object),
+		uploads: make(map[string]*upload),
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Read the body before locking, as the body could be streamed from another request to this server.
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "IncompleteBody", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q := r.URL.Query()
+	for _, v := range unsupportedSubresources {
+		if _, ok := q[v]; ok {
+			writeError(w, r, http.StatusNotImplemented, "NotImplemented", "sub-resource "+v+" is not implemented")
+			return
+		}
+	}
+	if r.Header.Get("X-Amz-Copy-Source") != "" {
+		writeError(w, r, http.StatusNotImplemented, "NotImplemented", "copy is not implemented")
+		return
+	}
+	if strings.Contains(r.Header.Get("Content-Encoding"), "aws-chunked") {
+		writeError(w, r, http.StatusNotImplemented, "NotImplemented", "aws-chunked encoding is not implemented")
+		return
+	}
+
+	name, key := splitPath(r.URL.Path)
+	if name == "" {
+		if r.Method != http.MethodGet {
+			writeError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed", "method is not allowed")
+			return
+		}
+		s.listBuckets(w)
+		return
+	}
+
+	if key == "" {
+		switch r.Method {
+		case http.MethodPut:
+			s.createBucket(w, r, name)
+			return
+		case http.MethodDelete:
+			s.deleteBucket(w, r, name)
+			return
+		}
+	}
+
+	b, ok := s.buckets[name]
+	if !ok {
+		writeError(w, r, http.StatusNotFound, "NoSuchBucket", "the specified bucket does not exist")
+		return
+	}
+
+	_, uploads := q["uploads"]
+	_, uploadID := q["uploadId"]
+	switch {
+	case key == "" && r.Method == http.MethodHead:
+	case key == "" && r.Method == http.MethodGet && uploads:
+		s.listMultipartUploads(w, r, b, name)
+	case key == "" && r.Method == http.MethodGet:
+		s.listObjects(w, r, b, name)
+	case key == "":
+		writeError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed", "method is not allowed")
+	case r.Method == http.MethodPost && uploads:
+		s.createMultipartUpload(w, r, b, name, key)
+	case r.Method == http.MethodPost && uploadID:
+		s.completeMultipartUpload(w, r, b, name, key, data)
+	case r.Method == http.MethodPut && uploadID:
+		s.uploadPart(w, r, b, key, data)
+	case r.Method == http.MethodGet && uploadID:
+		s.listParts(w, r, b, name, key)
+	case r.Method == http.MethodDelete && uploadID:
+		if u, ok := s.getUpload(w, r, b, key); ok {
+			delete(b.uploads, u.id)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	case r.Method == http.MethodPut:
+		s.putObject(w, r, b, key, data)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		s.getObject(w, r, b, key)
+	case r.Method == http.MethodDelete:
+		delete(b.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed", "method is not allowed")
+	}
+}
+
+func (s *Server) listBuckets(w http.ResponseWriter) {
+	type bucketResult struct {
+		Name         string
+		CreationDate string
+	}
+	result := struct {
+		XMLName xml.Name       `xml:"ListAllMyBucketsResult"`
+		Owner   owner          `xml:"Owner"`
+		Buckets []bucketResult `xml:"Buckets>Bucket"`
+	}{Owner: defaultOwner}
+
+	for _, name := range sortedKeys(s.buckets) {
+		result.Buckets = append(result.Buckets, bucketResult{
+			Name:         name,
+			CreationDate: s.buckets[name].created.UTC().Format(timeFormat),
+		})
+	}
+	writeXML(w, result)
+}
+
+func (s *Server) createBucket(w http.ResponseWriter, r *http.Request, name string) {
+	if _, ok := s.buckets[name]; ok {
+		writeError(w, r, http.StatusConflict, "BucketAlreadyOwnedByYou", "the bucket already exists")
+		return
+	}
+	s.buckets[name] = newBucket()
+	w.Header().Set("Location", "/"+name)
+}
+
+func (s *Server) deleteBucket(w http.ResponseWriter, r *http.Request, name string) {
+	b, ok := s.buckets[name]
+	if !ok {
+		writeError(w, r, http.StatusNotFound, "NoSuchBucket", "the specified bucket does not exist")
+		return
+	}
+	if len(b.objects) > 0 {
+		writeError(w, r, http.StatusConflict, "BucketNotEmpty", "the bucket is not empty")
+		return
+	}
+	delete(s.buckets, name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listObjects implements both ListObjects and ListObjectsV2.
+func (s *Server) listObjects(w http.ResponseWriter, r *http.Request, b *bucket, name string) {
+	q := r.URL.Query()
+	v2 := q.Get("list-type") == "2"
+
+	maxKeys, ok := parseMaxKeys(w, r, q.Get("max-keys"))
+	if !ok {
+		return
+	}
+	marker := q.Get("marker")
+	if v2 {
+		marker = q.Get("start-after")
+		if token := q.Get("continuation-token"); token != "" {
+			v, err := base64.StdEncoding.DecodeString(token)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "InvalidArgument", "the continuation token is not valid")
+				return
+			}
+			marker = string(v)
+		}
+	}
+
+	prefix, delimiter := q.Get("prefix"), q.Get("delimiter")
+	keys := make([]string, 0, len(b.objects))
+	for k := range b.objects {
+		keys = append(keys, k)
+	}
+	entries, truncated := paginate(keys, prefix, delimiter, marker, maxKeys)
+
+	encode := func(v string) string { return v }
+	if q.Get("encoding-type") == "url" {
+		encode = url.QueryEscape
+	}
+
+	type content struct {
+		Key          string
+		LastModified string
+		ETag         string
+		Size         int
+		StorageClass string
+		Owner        *owner `xml:",omitempty"`
+	}
+	type commonPrefix struct {
+		Prefix string
+	}
+	result := struct {
+		XMLName               xml.Name `xml:"ListBucketResult"`
+		Name                  string
+		Prefix                string
+		Delimiter             string `xml:",omitempty"`
+		MaxKeys               int
+		EncodingType          string `xml:",omitempty"`
+		IsTruncated           bool
+		Marker                *string `xml:",omitempty"`
+		NextMarker            string  `xml:",omitempty"`
+		StartAfter            string  `xml:",omitempty"`
+		ContinuationToken     string  `xml:",omitempty"`
+		NextContinuationToken string  `xml:",omitempty"`
+		KeyCount              *int    `xml:",omitempty"`
+		Contents              []content
+		CommonPrefixes        []commonPrefix
+	}{
+		Name:         name,
+		Prefix:       encode(prefix),
+		Delimiter:    encode(delimiter),
+		MaxKeys:      maxKeys,
+		EncodingType: q.Get("encoding-type"),
+		IsTruncated:  truncated,
+	}
+
+	// ListObjects always returns the owner, while ListObjectsV2 only returns it with fetch-owner.
+	var o *owner
+	if !v2 || q.Get("fetch-owner") == "true" {
+		o = &defaultOwner
+	}
+	for _, k := range entries {
+		// Keys containing the delimiter have been grouped into common prefixes.
+		if _, ok := commonPrefixOf(k, prefix, delimiter); ok {
+			result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: encode(k)})
+			continue
+		}
+		v := b.objects[k]
+		storageClass := v.header.Get("X-Amz-Storage-Class")
+		if storageClass == "" {
+			storageClass = "STANDARD"
+		}
+		result.Contents = append(result.Contents, content{
+			Key:          encode(k),
+			LastModified: v.modTime.UTC().Format(timeFormat),
+			ETag:         v.etag,
+			Size:         len(v.data),
+			StorageClass: storageClass,
+			Owner:        o,
+		})
+	}
+
+	var next string
+	if n := len(entries); n > 0 && truncated {
+		next = entries[n-1]
+	}
+	if v2 {
+		count := len(entries)
+		result.KeyCount = &count
+		result.StartAfter = encode(q.Get("start-after"))
+		result.ContinuationToken = q.Get("continuation-token")
+		if next != "" {
+			result.NextContinuationToken = base64.StdEncoding.EncodeToString([]byte(next))
+		}
+	} else {
+		result.Marker = &marker
+		// NextMarker is only returned with delimiter.
+		if delimiter != "" {
+			result.NextMarker = encode(next)
+		}
+	}
+	writeXML(w, result)
+}
+
+func (s *Server) putObject(w http.ResponseWriter, r *http.Request, b *bucket, key string, data []byte) {
+	if !checkContentMD5(w, r, data) {
+		return
+	}
+
+	if !checkWritePreconditions(w, r, b, key) {
+		return
+	}
+
+	o := &object{
+		data:    data,
+		etag:    md5ETag(data),
+		modTime: time.Now(),
+		header:  storedHeader(r.Header),
+	}
+	b.objects[key] = o
+	w.Header().Set("ETag", o.etag)
+}
+
+func (s *Server) getObject(w http.ResponseWriter, r *http.Request, b *bucket, key string) {
+	o, ok := b.objects[key]
+	if !ok {
+		writeError(w, r, http.StatusNotFound, "NoSuchKey", "the specified key does not exist")
+		return
+	}
+
+	h := w.Header()
+	for k, v := range o.header {
+		// The storage class is not returned for STANDARD objects.
+		if k == "X-Amz-Storage-Class" && v[0] == "STANDARD" {
+			continue
+		}
+		h[k] = v
+	}
+	h.Set("ETag", o.etag)
+	h.Set("Last-Modified", o.modTime.UTC().Format(http.TimeFormat))
+	h.Set("Accept-Ranges", "bytes")
+
+	// Conditions are evaluated in the order of RFC 7232 section 6.
+	modTime := o.modTime.Truncate(time.Second)
+	q := r.URL.Query()
+	if v := r.Header.Get("If-Match"); v != "" && !matchETag(v, o.etag) {
+		writeError(w, r, http.StatusPreconditionFailed, "PreconditionFailed", "at least one of the pre-conditions you specified did not hold")
+		return
+	}
+	if t, err := http.ParseTime(r.Header.Get("If-Unmodified-Since")); err == nil && r.Header.Get("If-Match") == "" && modTime.After(t) {
+		writeError(w, r, http.StatusPreconditionFailed, "PreconditionFailed", "at least one of the pre-conditions you specified did not hold")
+		return
+	}
+	if v := r.Header.Get("If-None-Match"); v != "" && matchETag(v, o.etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if t, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && r.Header.Get("If-None-Match") == "" && !modTime.After(t) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	for _, k := range []string{"Cache-Control", "Content-Disposition", "Content-Encoding", "Content-Language", "Content-Type", "Expires"} {
+		if v := q.Get("response-" + strings.ToLower(k)); v != "" {
+			h.Set(k, v)
+		}
+	}
+
+	data, status := o.data, http.StatusOK
+	if v := r.Header.Get("Range"); v != "" {
+		start, end, ok := parseRange(v, len(o.data))
+		if !ok {
+			h.Del("ETag")
+			writeError(w, r, http.StatusRequestedRangeNotSatisfiable, "InvalidRange", "the requested range is not satisfiable")
+			return
+		}
+		data, status = o.data[start:end], http.StatusPartialContent
+		h.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end-1, len(o.data)))
+	}
+	h.Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(data)
+	}
+}
+
+func (s *Server) createMultipartUpload(w http.ResponseWriter, r *http.Request, b *bucket, name, key string) {
+	s.uploadID++
+	u := &upload{
+		key: key,
+		// Upload IDs are sorted by the time initiated.
+		id:        fmt.Sprintf("%020d", s.uploadID),
+		initiated: time.Now(),
+		header:    storedHeader(r.Header),
+		parts:     make(map[int]*object),
+	}
+	b.uploads[u.id] = u
+
+	writeXML(w, struct {
+		XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+		Bucket   string
+		Key      string
+		UploadId string
+	}{Bucket: name, Key: key, UploadId: u.id})
+}
+
+func (s *Server) uploadPart(w http.ResponseWriter, r *http.Request, b *bucket, key string, data []byte) {
+	u, ok := s.getUpload(w, r, b, key)
+	if !ok {
+		return
+	}
+	number, err := strconv.Atoi(r.URL.Query().Get("partNumber"))
+	if err != nil || number < 1 || number > maxPartNumber {
+		writeError(w, r, http.StatusBadRequest, "InvalidArgument", "part number must be an integer between 1 and 10000")
+		return
+	}
+	if !checkContentMD5(w, r, data) {
+		return
+	}
+
+	p := &object{data: data, etag: md5ETag(data), modTime: time.Now()}
+	u.parts[number] = p
+	w.Header().Set("ETag", p.etag)
+}
+
+func (s *Server) completeMultipartUpload(w http.ResponseWriter, r *http.Request, b *bucket, name, key string, data []byte) {
+	u, ok := s.getUpload(w, r, b, key)
+	if !ok {
+		return
+	}
+	if !checkWritePreconditions(w, r, b, key) {
+		return
+	}
+
+	var input struct {
+		Parts []struct {
+			PartNumber int
+			ETag       string
+		} `xml:"Part"`
+	}
+	if err := xml.Unmarshal(data, &input); err != nil || len(input.Parts) == 0 {
+		writeError(w, r, http.StatusBadRequest, "MalformedXML", "the XML you provided was not well-formed")
+		return
+	}
+
+	var (
+		content bytes.Buffer
+		sums    []byte
+	)
+	for i, v := range input.Parts {
+		if i > 0 && v.PartNumber <= input.Parts[i-1].PartNumber {
+			writeError(w, r, http.StatusBadRequest, "InvalidPartOrder", "the list of parts was not in ascending order")
+			return
+		}
+		p, ok := u.parts[v.PartNumber]
+		if !ok || !matchETag(v.ETag, p.etag) {
+			writeError(w, r, http.StatusBadRequest, "InvalidPart", fmt.Sprintf("part %d could not be found", v.PartNumber))
+			return
+		}
+		if i < len(input.Parts)-1 && len(p.data) < minPartSize {
+			writeError(w, r, http.StatusBadRequest, "EntityTooSmall", fmt.Sprintf("part %d is smaller than the minimum allowed size", v.PartNumber))
+			return
+		}
+		content.Write(p.data)
+		sum := md5.Sum(p.data)
+		sums = append(sums, sum[:]...)
+	}
+
+	sum := md5.Sum(sums)
+	o := &object{
+		data:    content.Bytes(),
+		etag:    fmt.Sprintf(`"%s-%d"`, hex.EncodeToString(sum[:]), len(input.Parts)),
+		modTime: time.Now(),
+		header:  u.header,
+	}
+	b.objects[key] = o
+	delete(b.uploads, u.id)
+
+	writeXML(w, struct {
+		XMLName  xml.Name `xml:"CompleteMultipartUploadResult"`
+		Location string
+		Bucket   string
+		Key      string
+		ETag     string
+	}{Location: s.URL + "/" + name + "/" + key, Bucket: name, Key: key, ETag: o.etag})
+}
+
+func (s *Server) listParts(w http.ResponseWriter, r *http.Request, b *bucket, name, key string) {
+	u, ok := s.getUpload(w, r, b, key)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	maxParts, ok := parseMaxKeys(w, r, q.Get("max-parts"))
+	if !ok {
+		return
+	}
+	marker, _ := strconv.Atoi(q.Get("part-number-marker"))
+
+	type part struct {
+		PartNumber   int
+		LastModified string
+		ETag         string
+		Size         int
+	}
+	result := struct {
+		XMLName              xml.Name `xml:"ListPartsResult"`
+		Bucket               string
+		Key                  string
+		UploadId             string
+		PartNumberMarker     int
+		NextPartNumberMarker int
+		MaxParts             int
+		IsTruncated          bool
+		StorageClass         string
+		Parts                []part `xml:"Part"`
+	}{Bucket: name, Key: key, UploadId: u.id, PartNumberMarker: marker, MaxParts: maxParts, StorageClass: "STANDARD"}
+
+	numbers := make([]int, 0, len(u.parts))
+	for n := range u.parts {
+		if n > marker {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+	if len(numbers) > maxParts {
+		numbers, result.IsTruncated = numbers[:maxParts], true
+	}
+	for _, n := range numbers {
+		p := u.parts[n]
+		result.Parts = append(result.Parts, part{
+			PartNumber:   n,
+			LastModified: p.modTime.UTC().Format(timeFormat),
+			ETag:         p.etag,
+			Size:         len(p.data),
+		})
+		result.NextPartNumberMarker = n
+	}
+	writeXML(w, result)
+}
+
+func (s *Server) listMultipartUploads(w http.ResponseWriter, r *http.Request, b *bucket, name string) {
+	q := r.URL.Query()
+	maxUploads, ok := parseMaxKeys(w, r, q.Get("max-uploads"))
+	if !ok {
+		return
+	}
+	prefix, delimiter := q.Get("prefix"), q.Get("delimiter")
+	keyMarker, uploadIDMarker := q.Get("key-marker"), q.Get("upload-id-marker")
+
+	type uploadResult struct {
+		Key          string
+		UploadId     string
+		Initiator    owner
+		Owner        owner
+		StorageClass string
+		Initiated    string
+	}
+	type commonPrefix struct {
+		Prefix string
+	}
+	result := struct {
+		XMLName            xml.Name `xml:"ListMultipartUploadsResult"`
+		Bucket             string
+		KeyMarker          string
+		UploadIdMarker     string
+		NextKeyMarker      string
+		NextUploadIdMarker string
+		Prefix             string
+		Delimiter          string `xml:",omitempty"`
+		MaxUploads         int
+		IsTruncated        bool
+		Uploads            []uploadResult `xml:"Upload"`
+		CommonPrefixes     []commonPrefix
+	}{
+		Bucket:         name,
+		KeyMarker:      keyMarker,
+		UploadIdMarker: uploadIDMarker,
+		Prefix:         prefix,
+		Delimiter:      delimiter,
+		MaxUploads:     maxUploads,
+	}
+
+	uploads := make([]*upload, 0, len(b.uploads))
+	for _, u := range b.uploads {
+		uploads = append(uploads, u)
+	}
+	sort.Slice(uploads, func(i, j int) bool {
+		if uploads[i].key != uploads[j].key {
+			return uploads[i].key < uploads[j].key
+		}
+		return uploads[i].id < uploads[j].id
+	})
+
+	seen := make(map[string]bool)
+	for _, u := range uploads {
+		if !strings.HasPrefix(u.key, prefix) {
+			continue
+		}
+		// Uploads of the key marker are skipped unless upload-id-marker is specified, see ListMultipartUploads.
+		if u.key < keyMarker || u.key == keyMarker && (uploadIDMarker == "" || u.id <= uploadIDMarker) {
+			continue
+		}
+		if p, ok := commonPrefixOf(u.key, prefix, delimiter); ok {
+			if seen[p] || p <= keyMarker {
+				continue
+			}
+			if len(result.Uploads)+len(result.CommonPrefixes) == maxUploads {
+				result.IsTruncated = true
+				break
+			}
+			seen[p] = true
+			result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: p})
+			result.NextKeyMarker, result.NextUploadIdMarker = p, ""
+			continue
+		}
+		if len(result.Uploads)+len(result.CommonPrefixes) == maxUploads {
+			result.IsTruncated = true
+			break
+		}
+		storageClass := u.header.Get("X-Amz-Storage-Class")
+		if storageClass == "" {
+			storageClass = "STANDARD"
+		}
+		result.Uploads = append(result.Uploads, uploadResult{
+			Key:          u.key,
+			UploadId:     u.id,
+			Initiator:    defaultOwner,
+			Owner:        defaultOwner,
+			StorageClass: storageClass,
+			Initiated:    u.initiated.UTC().Format(timeFormat),
+		})
+		result.NextKeyMarker, result.NextUploadIdMarker = u.key, u.id
+	}
+	writeXML(w, result)
+}
+
+// getUpload will return the upload specified by uploadId, and write NoSuchUpload if not found.
+func (s *Server) getUpload(w http.ResponseWriter, r *http.Request, b *bucket, key string) (*upload, bool) {
+	u, ok := b.uploads[r.URL.Query().Get("uploadId")]
+	if !ok || u.key != key {
+		writeError(w, r, http.StatusNotFound, "NoSuchUpload", "the specified multipart upload does not exist")
+		return nil, false
+	}
+	return u, true
+}
+
+// paginate will return the sorted keys and common prefixes after marker, up to maxKeys entries.
+func paginate(keys []string, prefix, delimiter, marker string, maxKeys int) (entries []string, truncated bool) {
+	sort.Strings(keys)
+
+	var last string
+	for _, k := range keys {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		if p, ok := commonPrefixOf(k, prefix, delimiter); ok {
+			k = p
+		}
+		// Keys under the same common prefix are sorted together, so only compare with the last one.
+		if k <= marker || k == last {
+			continue
+		}
+		if len(entries) == maxKeys {
+			return entries, true
+		}
+		entries = append(entries, k)
+		last = k
+	}
+	return entries, false
+}
+
+// commonPrefixOf will return the common prefix of key if the delimiter is found after prefix.
+func commonPrefixOf(key, prefix, delimiter string) (string, bool) {
+	if delimiter == "" {
+		return "", false
+	}
+	i := strings.Index(key[len(prefix):], delimiter)
+	if i < 0 {
+		return "", false
+	}
+	return key[:len(prefix)+i+len(delimiter)], true
+}
+
+// splitPath will split the path style URL path into bucket and key.
+func splitPath(p string) (name, key string) {
+	p = strings.TrimPrefix(p, "/")
+	if i := strings.Index(p, "/"); i >= 0 {
+		return p[:i], p[i+1:]
+	}
+	return p, ""
+}
+
+// storedHeader will return the headers of h which should be stored with the object, including user metadata.
+func storedHeader(h http.Header) http.Header {
+	header := make(http.Header)
+	for _, k := range storedHeaders {
+		if v := h.Get(k); v != "" {
+			header.Set(k, v)
+		}
+	}
+	for k, v := range h {
+		if strings.HasPrefix(k, "X-Amz-Meta-") {
+			header[k] = v
+		}
+	}
+	return header
+}
+
+// parseRange will parse a single range of "bytes=start-end", "bytes=start-" or "bytes=-suffix".
+func parseRange(h string, size int) (start, end int, ok bool) {
+	if !strings.HasPrefix(h, "bytes=") || strings.Contains(h, ",") {
+		return 0, 0, false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(h, "bytes="), "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+
+	if parts[0] == "" {
+		suffix, err := strconv.Atoi(parts[1])
+		if err != nil || suffix <= 0 || size == 0 {
+			return 0, 0, false
+		}
+		if suffix > size {
+			suffix = size
+		}
+		return size - suffix, size, true
+	}
+
+	start, err := strconv.Atoi(parts[0])
+	if err != nil || start < 0 || start >= size {
+		return 0, 0, false
+	}
+	end = size
+	if parts[1] != "" {
+		last, err := strconv.Atoi(parts[1])
+		if err != nil || last < start {
+			return 0, 0, false
+		}
+		if last+1 < end {
+			end = last + 1
+		}
+	}
+	return start, end, true
+}
+
+func parseMaxKeys(w http.ResponseWriter, r *http.Request, v string) (int, bool) {
+	if v == "" {
+		return defaultMaxKeys, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		writeError(w, r, http.StatusBadRequest, "InvalidArgument", "max keys must be a non-negative integer")
+		return 0, false
+	}
+	if n > defaultMaxKeys {
+		n = defaultMaxKeys
+	}
+	return n, true
+}
+
+// checkContentMD5 will check the Content-MD5 header if present, and write BadDigest if not matched.
+func checkContentMD5(w http.ResponseWriter, r *http.Request, data []byte) bool {
+	v := r.Header.Get("Content-MD5")
+	if v == "" {
+		return true
+	}
+	sum := md5.Sum(data)
+	if v != base64.StdEncoding.EncodeToString(sum[:]) {
+		writeError(w, r, http.StatusBadRequest, "BadDigest", "the Content-MD5 you specified did not match what we received")
+		return false
+	}
+	return true
+}
+
+// checkWritePreconditions will check If-None-Match and If-Match of conditional writes against the current object,
+// and write PreconditionFailed if any of them doesn't hold.
+func checkWritePreconditions(w http.ResponseWriter, r *http.Request, b *bucket, key string) bool {
+	old, exist := b.objects[key]
+	if v := r.Header.Get("If-None-Match"); v == "*" && exist {
+		writeError(w, r, http.StatusPreconditionFailed, "PreconditionFailed", "at least one of the pre-conditions you specified did not hold")
+		return false
+	}
+	if v := r.Header.Get("If-Match"); v != "" && (!exist || !matchETag(v, old.etag)) {
+		writeError(w, r, http.StatusPreconditionFailed, "PreconditionFailed", "at least one of the pre-conditions you specified did not hold")
+		return false
+	}
+	return true
+}
+
+func md5ETag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// matchETag reports whether the ETag condition matches etag, the condition could be "*" or a list of ETags.
+func matchETag(condition, etag string) bool {
+	for _, v := range strings.Split(condition, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.Trim(v, `"`) == strings.Trim(etag, `"`) {
+			return true
+		}
+	}
+	return false
+}
+
+type owner struct {
+	ID          string
+	DisplayName string
+}
+
+var defaultOwner = owner{ID: "s3test", DisplayName: "s3test"}
+
+func sortedKeys(m map[string]*bucket) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeXML(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	_, _ = w.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(w).Encode(v)
+}
+
+// writeError will write the error in S3 error response format, HEAD responses don't have a body.
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	if r.Method == http.MethodHead {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName  xml.Name `xml:"Error"`
+		Code     string
+		Message  string
+		Resource string
+	}{Code: code, Message: message, Resource: r.URL.Path})
+}
@@ -0,0 +1,141 @@
+package s3test_test
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"testing"
+
+	s3 "github.com/beyondstorage/go-service-s3/v2"
+	"github.com/beyondstorage/go-service-s3/v2/s3test"
+	ps "github.com/beyondstorage/go-storage/v4/pairs"
+	"github.com/beyondstorage/go-storage/v4/services"
+	"github.com/beyondstorage/go-storage/v4/types"
+)
+
+func newServicer(t *testing.T, srv *s3test.Server) types.Servicer {
+	servicer, err := s3.NewServicer(
+		ps.WithCredential("hmac:access_key:secret_key"),
+		ps.WithEndpoint(srv.Endpoint()),
+		ps.WithLocation("us-east-1"),
+		s3.WithForcePathStyle(),
+	)
+	if err != nil {
+		t.Fatalf("new servicer: %v", err)
+	}
+	return servicer
+}
+
+// listPaths will list all paths under path, and return them sorted.
+func listPaths(t *testing.T, store types.Storager, path string, pairs ...types.Pair) []string {
+	t.Helper()
+
+	it, err := store.List(path, pairs...)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	var paths []string
+	for {
+		o, err := it.Next()
+		if errors.Is(err, types.IterateDone) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		paths = append(paths, o.Path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestServer(t *testing.T) {
+	srv := s3test.NewServer("existing")
+	defer srv.Close()
+	servicer := newServicer(t, srv)
+
+	store, err := servicer.Create("bucket", ps.WithLocation("us-east-1"))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err = servicer.Create("bucket", ps.WithLocation("us-east-1")); err == nil {
+		t.Errorf("create: expected error for existing bucket")
+	}
+
+	for _, p := range []string{"a", "b/", "b/c", "b/d/e", "f/g"} {
+		if _, err = store.Write(p, bytes.NewReader([]byte(p)), int64(len(p))); err != nil {
+			t.Fatalf("write %s: %v", p, err)
+		}
+	}
+	// max_keys is small enough to make sure common prefixes are not returned twice across pages.
+	if paths := listPaths(t, store, "", ps.WithListMode(types.ListModeDir), s3.WithMaxKeys(1)); len(paths) != 3 ||
+		paths[0] != "a" || paths[1] != "b/" || paths[2] != "f/" {
+		t.Errorf("list dir: unexpected %v", paths)
+	}
+	if paths := listPaths(t, store, "b/", ps.WithListMode(types.ListModeDir), s3.WithMaxKeys(1)); len(paths) != 3 {
+		t.Errorf("list dir b/: unexpected %v", paths)
+	}
+	if paths := listPaths(t, store, "", ps.WithListMode(types.ListModePrefix), s3.WithMaxKeys(2)); len(paths) != 5 {
+		t.Errorf("list prefix: unexpected %v", paths)
+	}
+
+	m := store.(types.Multiparter)
+	for _, p := range []string{"x", "x", "y"} {
+		if _, err = m.CreateMultipart(p); err != nil {
+			t.Fatalf("create multipart: %v", err)
+		}
+	}
+	if paths := listPaths(t, store, "", ps.WithListMode(types.ListModePart), s3.WithMaxKeys(1)); len(paths) != 3 {
+		t.Errorf("list part: unexpected %v", paths)
+	}
+
+	if err = servicer.Delete("bucket"); err == nil {
+		t.Errorf("delete: expected error for non-empty bucket")
+	}
+	if _, err = store.Stat("missing"); !errors.Is(err, services.ErrObjectNotExist) {
+		t.Errorf("stat: expected %v, actual %v", services.ErrObjectNotExist, err)
+	}
+
+	if err = servicer.Delete("existing"); err != nil {
+		t.Errorf("delete: %v", err)
+	}
+}
+
+func TestServerConditionalCompleteMultipart(t *testing.T) {
+	srv := s3test.NewServer("bucket")
+	defer srv.Close()
+	store, err := newServicer(t, srv).Get("bucket", ps.WithLocation("us-east-1"))
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if _, err = store.Write("a", bytes.NewReader([]byte("a")), 1); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	complete := func(pairs ...types.Pair) error {
+		m := store.(types.Multiparter)
+		o, err := m.CreateMultipart("a")
+		if err != nil {
+			t.Fatalf("create multipart: %v", err)
+		}
+		_, part, err := m.WriteMultipart(o, bytes.NewReader([]byte("b")), 1, 0)
+		if err != nil {
+			t.Fatalf("write multipart: %v", err)
+		}
+		return m.CompleteMultipart(o, []*types.Part{part}, pairs...)
+	}
+
+	if err = complete(s3.WithIfNoneMatch("*")); !errors.Is(err, s3.ErrPreconditionFailed) {
+		t.Errorf("complete with if_none_match: expected %v, actual %v", s3.ErrPreconditionFailed, err)
+	}
+	if err = complete(s3.WithIfMatch(`"mismatch"`)); !errors.Is(err, s3.ErrPreconditionFailed) {
+		t.Errorf("complete with if_match: expected %v, actual %v", s3.ErrPreconditionFailed, err)
+	}
+	o, err := store.Stat("a")
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if err = complete(s3.WithIfMatch(o.MustGetEtag())); err != nil {
+		t.Errorf("complete with if_match: %v", err)
+	}
+}
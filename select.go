@@ -0,0 +1,336 @@
+package s3
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/beyondstorage/go-storage/v4/services"
+	. "github.com/beyondstorage/go-storage/v4/types"
+)
+
+// All available compression types of the queried object are listed here.
+const (
+	SelectCompressionTypeNone  = s3types.CompressionTypeNone
+	SelectCompressionTypeGzip  = s3types.CompressionTypeGzip
+	SelectCompressionTypeBzip2 = s3types.CompressionTypeBzip2
+)
+
+// All available file header infos of CSV input are listed here.
+const (
+	SelectFileHeaderInfoUse    = s3types.FileHeaderInfoUse
+	SelectFileHeaderInfoIgnore = s3types.FileHeaderInfoIgnore
+	SelectFileHeaderInfoNone   = s3types.FileHeaderInfoNone
+)
+
+// All available JSON types of JSON input are listed here.
+const (
+	SelectJSONTypeLines    = s3types.JSONTypeLines
+	SelectJSONTypeDocument = s3types.JSONTypeDocument
+)
+
+// All available quote fields of CSV output are listed here.
+const (
+	SelectQuoteFieldsAlways   = s3types.QuoteFieldsAlways
+	SelectQuoteFieldsAsNeeded = s3types.QuoteFieldsAsneeded
+)
+
+// SelectInputSerialization is the format of the queried object,
+// could be *SelectCSVInput, *SelectJSONInput or *SelectParquetInput.
+type SelectInputSerialization interface {
+	formatInputSerialization() *s3types.InputSerialization
+}
+
+// SelectOutputSerialization is the format of the query results, could be *SelectCSVOutput or *SelectJSONOutput.
+type SelectOutputSerialization interface {
+	formatOutputSerialization() *s3types.OutputSerialization
+}
+
+// SelectCSVInput describes how a CSV object is formatted. Empty fields will use S3's default values.
+type SelectCSVInput struct {
+	// FileHeaderInfo could be SelectFileHeaderInfoUse, SelectFileHeaderInfoIgnore or SelectFileHeaderInfoNone.
+	FileHeaderInfo             s3types.FileHeaderInfo
+	FieldDelimiter             string
+	RecordDelimiter            string
+	QuoteCharacter             string
+	QuoteEscapeCharacter       string
+	Comments                   string
+	AllowQuotedRecordDelimiter bool
+
+	CompressionType s3types.CompressionType
+}
+
+func (i *SelectCSVInput) formatInputSerialization() *s3types.InputSerialization {
+	return &s3types.InputSerialization{
+		CSV: &s3types.CSVInput{
+			FileHeaderInfo:             i.FileHeaderInfo,
+			FieldDelimiter:             formatOptionalString(i.FieldDelimiter),
+			RecordDelimiter:            formatOptionalString(i.RecordDelimiter),
+			QuoteCharacter:             formatOptionalString(i.QuoteCharacter),
+			QuoteEscapeCharacter:       formatOptionalString(i.QuoteEscapeCharacter),
+			Comments:                   formatOptionalString(i.Comments),
+			AllowQuotedRecordDelimiter: i.AllowQuotedRecordDelimiter,
+		},
+		CompressionType: i.CompressionType,
+	}
+}
+
+// SelectJSONInput describes how a JSON object is formatted.
+type SelectJSONInput struct {
+	// Type could be SelectJSONTypeLines or SelectJSONTypeDocument, SelectJSONTypeLines by default.
+	Type s3types.JSONType
+
+	CompressionType s3types.CompressionType
+}
+
+func (i *SelectJSONInput) formatInputSerialization() *s3types.InputSerialization {
+	t := i.Type
+	if t == "" {
+		t = SelectJSONTypeLines
+	}
+	return &s3types.InputSerialization{
+		JSON:            &s3types.JSONInput{Type: t},
+		CompressionType: i.CompressionType,
+	}
+}
+
+// SelectParquetInput describes a Parquet object.
+type SelectParquetInput struct{}
+
+func (i *SelectParquetInput) formatInputSerialization() *s3types.InputSerialization {
+	return &s3types.InputSerialization{
+		Parquet: &s3types.ParquetInput{},
+	}
+}
+
+// SelectCSVOutput describes how the query results are formatted as CSV. Empty fields will use S3's default values.
+type SelectCSVOutput struct {
+	// QuoteFields could be SelectQuoteFieldsAlways or SelectQuoteFieldsAsNeeded.
+	QuoteFields          s3types.QuoteFields
+	FieldDelimiter       string
+	RecordDelimiter      string
+	QuoteCharacter       string
+	QuoteEscapeCharacter string
+}
+
+func (o *SelectCSVOutput) formatOutputSerialization() *s3types.OutputSerialization {
+	return &s3types.OutputSerialization{
+		CSV: &s3types.CSVOutput{
+			QuoteFields:          o.QuoteFields,
+			FieldDelimiter:       formatOptionalString(o.FieldDelimiter),
+			RecordDelimiter:      formatOptionalString(o.RecordDelimiter),
+			QuoteCharacter:       formatOptionalString(o.QuoteCharacter),
+			QuoteEscapeCharacter: formatOptionalString(o.QuoteEscapeCharacter),
+		},
+	}
+}
+
+// SelectJSONOutput describes how the query results are formatted as JSON lines.
+type SelectJSONOutput struct {
+	// RecordDelimiter is "\n" by default.
+	RecordDelimiter string
+}
+
+func (o *SelectJSONOutput) formatOutputSerialization() *s3types.OutputSerialization {
+	return &s3types.OutputSerialization{
+		JSON: &s3types.JSONOutput{
+			RecordDelimiter: formatOptionalString(o.RecordDelimiter),
+		},
+	}
+}
+
+// SelectStats is the bytes scanned, processed and returned by a select query.
+type SelectStats struct {
+	BytesScanned   int64
+	BytesProcessed int64
+	BytesReturned  int64
+	// Final is true for the Stats event sent at the end of the query, false for Progress events.
+	Final bool
+}
+
+func formatOptionalString(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return aws.String(v)
+}
+
+// pairStorageSelect is the parsed struct for SelectObjectContent.
+type pairStorageSelect struct {
+	pairs []Pair
+	// Optional pairs
+	HasExceptedBucketOwner                   bool
+	ExceptedBucketOwner                      string
+	HasOffset                                bool
+	Offset                                   int64
+	HasSelectStatsCallback                   bool
+	SelectStatsCallback                      func(SelectStats)
+	HasServerSideEncryptionCustomerAlgorithm bool
+	ServerSideEncryptionCustomerAlgorithm    string
+	HasServerSideEncryptionCustomerKey       bool
+	ServerSideEncryptionCustomerKey          []byte
+	HasSize                                  bool
+	Size                                     int64
+}
+
+func (s *Storage) parsePairStorageSelect(opts []Pair) (pairStorageSelect, error) {
+	result := pairStorageSelect{pairs: opts}
+
+	for _, v := range opts {
+		switch v.Key {
+		case "excepted_bucket_owner":
+			if result.HasExceptedBucketOwner {
+				continue
+			}
+			result.HasExceptedBucketOwner = true
+			result.ExceptedBucketOwner = v.Value.(string)
+		case "offset":
+			if result.HasOffset {
+				continue
+			}
+			result.HasOffset = true
+			result.Offset = v.Value.(int64)
+		case "select_stats_callback":
+			if result.HasSelectStatsCallback {
+				continue
+			}
+			result.HasSelectStatsCallback = true
+			result.SelectStatsCallback = v.Value.(func(SelectStats))
+		case "server_side_encryption_customer_algorithm":
+			if result.HasServerSideEncryptionCustomerAlgorithm {
+				continue
+			}
+			result.HasServerSideEncryptionCustomerAlgorithm = true
+			result.ServerSideEncryptionCustomerAlgorithm = v.Value.(string)
+		case "server_side_encryption_customer_key":
+			if result.HasServerSideEncryptionCustomerKey {
+				continue
+			}
+			result.HasServerSideEncryptionCustomerKey = true
+			result.ServerSideEncryptionCustomerKey = v.Value.([]byte)
+		case "size":
+			if result.HasSize {
+				continue
+			}
+			result.HasSize = true
+			result.Size = v.Value.(int64)
+		default:
+			return pairStorageSelect{}, services.PairUnsupportedError{Pair: v}
+		}
+	}
+
+	return result, nil
+}
+
+// SelectObjectContent will filter the content of the object with a SQL expression, and write the results into w.
+//
+// Use WithOffset and WithSize to scan only a range of an uncompressed CSV or JSON lines object,
+// and WithSelectStatsCallback to receive the Progress and Stats events. ErrSelectIncomplete will be returned
+// if the stream is closed before the query is completed.
+func (s *Storage) SelectObjectContent(path string, expression string, input SelectInputSerialization, output SelectOutputSerialization, w io.Writer, pairs ...Pair) (n int64, err error) {
+	ctx := context.Background()
+	return s.SelectObjectContentWithContext(ctx, path, expression, input, output, w, pairs...)
+}
+
+// SelectObjectContentWithContext will filter the content of the object with a SQL expression, and write the results into w.
+//
+// Use WithOffset and WithSize to scan only a range of an uncompressed CSV or JSON lines object,
+// and WithSelectStatsCallback to receive the Progress and Stats events. ErrSelectIncomplete will be returned
+// if the stream is closed before the query is completed.
+func (s *Storage) SelectObjectContentWithContext(ctx context.Context, path string, expression string, input SelectInputSerialization, output SelectOutputSerialization, w io.Writer, pairs ...Pair) (n int64, err error) {
+	defer func() {
+		err = s.formatError("select_object_content", err, path)
+	}()
+
+	opt, err := s.parsePairStorageSelect(pairs)
+	if err != nil {
+		return
+	}
+	return s.selectObjectContent(ctx, strings.ReplaceAll(path, "\\", "/"), expression, input, output, w, opt)
+}
+
+func (s *Storage) formatSelectObjectContentInput(path string, expression string, is SelectInputSerialization, os SelectOutputSerialization, opt pairStorageSelect) (input *s3.SelectObjectContentInput, err error) {
+	input = &s3.SelectObjectContentInput{
+		Bucket:              aws.String(s.name),
+		Key:                 aws.String(s.getAbsPath(path)),
+		Expression:          aws.String(expression),
+		ExpressionType:      s3types.ExpressionTypeSql,
+		InputSerialization:  is.formatInputSerialization(),
+		OutputSerialization: os.formatOutputSerialization(),
+	}
+	if opt.HasOffset || opt.HasSize {
+		// ScanRange's End is inclusive, and an empty End means to the end of the object.
+		input.ScanRange = &s3types.ScanRange{Start: opt.Offset}
+		if opt.HasSize {
+			input.ScanRange.End = opt.Offset + opt.Size - 1
+		}
+	}
+	if opt.HasSelectStatsCallback {
+		input.RequestProgress = &s3types.RequestProgress{Enabled: true}
+	}
+	if opt.HasExceptedBucketOwner {
+		input.ExpectedBucketOwner = &opt.ExceptedBucketOwner
+	}
+	if opt.HasServerSideEncryptionCustomerAlgorithm {
+		input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5, err = calculateEncryptionHeaders(opt.ServerSideEncryptionCustomerAlgorithm, opt.ServerSideEncryptionCustomerKey)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (s *Storage) selectObjectContent(ctx context.Context, path string, expression string, is SelectInputSerialization, os SelectOutputSerialization, w io.Writer, opt pairStorageSelect) (n int64, err error) {
+	input, err := s.formatSelectObjectContentInput(path, expression, is, os, opt)
+	if err != nil {
+		return
+	}
+	output, err := s.service.SelectObjectContent(ctx, input)
+	if err != nil {
+		return
+	}
+
+	stream := output.GetStream()
+	defer stream.Close()
+
+	for event := range stream.Events() {
+		switch v := event.(type) {
+		case *s3types.SelectObjectContentEventStreamMemberRecords:
+			written, err := w.Write(v.Value.Payload)
+			n += int64(written)
+			if err != nil {
+				return n, err
+			}
+		case *s3types.SelectObjectContentEventStreamMemberProgress:
+			if opt.HasSelectStatsCallback && v.Value.Details != nil {
+				opt.SelectStatsCallback(SelectStats{
+					BytesScanned:   v.Value.Details.BytesScanned,
+					BytesProcessed: v.Value.Details.BytesProcessed,
+					BytesReturned:  v.Value.Details.BytesReturned,
+				})
+			}
+		case *s3types.SelectObjectContentEventStreamMemberStats:
+			if opt.HasSelectStatsCallback && v.Value.Details != nil {
+				opt.SelectStatsCallback(SelectStats{
+					BytesScanned:   v.Value.Details.BytesScanned,
+					BytesProcessed: v.Value.Details.BytesProcessed,
+					BytesReturned:  v.Value.Details.BytesReturned,
+					Final:          true,
+				})
+			}
+		case *s3types.SelectObjectContentEventStreamMemberEnd:
+			// End event means the query is completed, and no more events will be sent.
+			return n, stream.Err()
+		}
+	}
+
+	if err = stream.Err(); err != nil {
+		return n, err
+	}
+	// The results are incomplete if End event is not received.
+	return n, ErrSelectIncomplete
+}
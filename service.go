@@ -62,7 +62,8 @@ func (s *Service) nextStoragePage(ctx context.Context, page *StoragerPage) error
 		return err
 	}
 	for _, v := range output.Buckets {
-		store, err := s.newStorage(ps.WithName(*v.Name))
+		// Location is required by storage, use the region of service as ListBuckets doesn't return it.
+		store, err := s.newStorage(ps.WithName(*v.Name), ps.WithLocation(s.cfg.Region))
 		if err != nil {
 			return err
 		}
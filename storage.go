@@ -3,14 +3,19 @@ package s3
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 
 	ps "github.com/beyondstorage/go-storage/v4/pairs"
 	"github.com/beyondstorage/go-storage/v4/pkg/iowrap"
@@ -20,7 +25,12 @@ import (
 
 func (s *Storage) completeMultipart(ctx context.Context, o *Object, parts []*Part, opt pairStorageCompleteMultipart) (err error) {
 	input := s.formatCompleteMultipartUploadInput(o, parts, opt)
-	_, err = s.service.CompleteMultipartUpload(ctx, input)
+
+	optFns, err := formatConditionalWriteOptions(opt.HasIfMatch, opt.IfMatch, opt.HasIfNoneMatch, opt.IfNoneMatch)
+	if err != nil {
+		return
+	}
+	_, err = s.service.CompleteMultipartUpload(ctx, input, optFns...)
 	if err != nil {
 		return
 	}
@@ -69,6 +79,15 @@ func (s *Storage) createDir(ctx context.Context, path string, opt pairStorageCre
 	//ref: https://docs.aws.amazon.com/AmazonS3/latest/userguide/using-folders.html
 	rp += "/"
 
+	if opt.HasImplicitDir && opt.ImplicitDir {
+		// The dir will exist implicitly once it has children, so there is no need to write marker.
+		o = s.newObject(true)
+		o.Mode = ModeDir
+		o.ID = rp
+		o.Path = path
+		return o, nil
+	}
+
 	input := &s3.PutObjectInput{
 		Bucket:        aws.String(s.name),
 		Key:           aws.String(rp),
@@ -80,6 +99,24 @@ func (s *Storage) createDir(ctx context.Context, path string, opt pairStorageCre
 	if opt.HasExceptedBucketOwner {
 		input.ExpectedBucketOwner = &opt.ExceptedBucketOwner
 	}
+	if opt.HasUserMetadata {
+		input.Metadata = opt.UserMetadata
+	}
+	if opt.HasACL {
+		input.ACL = s3types.ObjectCannedACL(opt.ACL)
+	}
+	if opt.HasGrantFullControl {
+		input.GrantFullControl = &opt.GrantFullControl
+	}
+	if opt.HasGrantRead {
+		input.GrantRead = &opt.GrantRead
+	}
+	if opt.HasGrantReadAcp {
+		input.GrantReadACP = &opt.GrantReadAcp
+	}
+	if opt.HasGrantWriteAcp {
+		input.GrantWriteACP = &opt.GrantWriteAcp
+	}
 	output, err := s.service.PutObject(ctx, input)
 	if err != nil {
 		return
@@ -89,6 +126,9 @@ func (s *Storage) createDir(ctx context.Context, path string, opt pairStorageCre
 	o.ID = rp
 	o.Path = path
 	o.SetEtag(aws.ToString(output.ETag))
+	if opt.HasUserMetadata {
+		o.SetUserMetadata(opt.UserMetadata)
+	}
 	var sm ObjectSystemMetadata
 	//output.ServerSideEncryption's type is s3types.ServerSideEncryption, which is equivalent to string
 	sm.ServerSideEncryption = string(output.ServerSideEncryption)
@@ -116,6 +156,11 @@ const metadataLinkTargetHeader = "x-amz-meta-bs-link-target"
 func (s *Storage) createLink(ctx context.Context, path string, target string, opt pairStorageCreateLink) (o *Object, err error) {
 	rt := s.getAbsPath(target)
 	rp := s.getAbsPath(path)
+	// Relative targets starting with "./" or "../" are stored as-is, and will be resolved from the dir of the
+	// link while following, so the link is still valid after its dir is moved together with the target.
+	if isRelativeLinkTarget(target) {
+		rt = target
+	}
 
 	input := &s3.PutObjectInput{
 		Bucket: aws.String(s.name),
@@ -141,7 +186,7 @@ func (s *Storage) createLink(ctx context.Context, path string, target string, op
 	} else {
 		// s3 does not have an absolute path, so when we call `getAbsPath`, it will remove the prefix `/`.
 		// To ensure that the path matches the one the user gets, we should re-add `/` here.
-		o.SetLinkTarget("/" + rt)
+		o.SetLinkTarget("/" + resolveLinkTarget(rp, rt))
 		o.Mode |= ModeLink
 	}
 
@@ -184,6 +229,9 @@ func (s *Storage) createMultipart(ctx context.Context, path string, opt pairStor
 	o.Path = path
 	o.Mode |= ModePart
 	o.SetMultipartID(aws.ToString(output.UploadId))
+	if opt.HasUserMetadata {
+		o.SetUserMetadata(opt.UserMetadata)
+	}
 	var sm ObjectSystemMetadata
 	//output.ServerSideEncryption's type is s3types.ServerSideEncryption, which is equivalent to string
 	sm.ServerSideEncryption = string(output.ServerSideEncryption)
@@ -209,13 +257,15 @@ func (s *Storage) delete(ctx context.Context, path string, opt pairStorageDelete
 	if opt.HasMultipartID {
 		abortInput := s.formatAbortMultipartUploadInput(path, opt)
 
-		// S3 AbortMultipartUpload is idempotent, so we don't need to check NoSuchUpload error.
+		// S3 AbortMultipartUpload returns NoSuchUpload for uploads that have been aborted or completed,
+		// which should be treated as deleted.
 		//
 		// References
 		// - [GSP-46](https://github.com/beyondstorage/specs/blob/master/rfcs/46-idempotent-delete.md)
 		// - https://docs.aws.amazon.com/AmazonS3/latest/API/API_AbortMultipartUpload.html
 		_, err = s.service.AbortMultipartUpload(ctx, abortInput)
-		if err != nil {
+		var ae smithy.APIError
+		if err != nil && !(errors.As(err, &ae) && ae.ErrorCode() == "NoSuchUpload") {
 			return
 		}
 	}
@@ -239,13 +289,38 @@ func (s *Storage) delete(ctx context.Context, path string, opt pairStorageDelete
 
 func (s *Storage) list(ctx context.Context, path string, opt pairStorageList) (oi *ObjectIterator, err error) {
 	input := &objectPageStatus{
-		maxKeys: 200,
+		maxKeys: listMaxKeysDefault,
 		prefix:  s.getAbsPath(path),
 	}
 
 	if opt.HasExceptedBucketOwner {
 		input.expectedBucketOwner = opt.ExceptedBucketOwner
 	}
+	if opt.HasMaxKeys {
+		if opt.MaxKeys <= 0 || opt.MaxKeys > listMaxKeysMaximum {
+			return nil, fmt.Errorf("max keys %d out of range (0, %d]: %w", opt.MaxKeys, listMaxKeysMaximum, services.ErrRestrictionDissatisfied)
+		}
+		input.maxKeys = int64(opt.MaxKeys)
+	}
+	if opt.HasStartAfter {
+		input.startAfter = s.getAbsPath(opt.StartAfter)
+	}
+	if opt.HasEncodingType {
+		if opt.EncodingType != EncodingTypeURL {
+			return nil, fmt.Errorf("encoding type %s not supported: %w", opt.EncodingType, services.ErrCapabilityInsufficient)
+		}
+		input.encodingType = opt.EncodingType
+	}
+	if opt.HasFetchOwner {
+		input.fetchOwner = opt.FetchOwner
+	}
+	if opt.HasResolveMode {
+		input.resolveMode = opt.ResolveMode
+		input.concurrency = defaultResolveModeConcurrency
+		if opt.HasConcurrency && opt.Concurrency > 0 {
+			input.concurrency = opt.Concurrency
+		}
+	}
 
 	if !opt.HasListMode {
 		// Support `ListModePrefix` as the default `ListMode`.
@@ -253,6 +328,14 @@ func (s *Storage) list(ctx context.Context, path string, opt pairStorageList) (o
 		opt.ListMode = ListModePrefix
 	}
 
+	if opt.HasContinuationToken {
+		if opt.ListMode.IsPart() {
+			input.setPartContinuationToken(opt.ContinuationToken)
+		} else {
+			input.continuationToken = opt.ContinuationToken
+		}
+	}
+
 	var nextFn NextObjectFunc
 
 	switch {
@@ -299,76 +382,87 @@ func (s *Storage) metadata(opt pairStorageMetadata) (meta *StorageMeta) {
 func (s *Storage) nextObjectPageByDir(ctx context.Context, page *ObjectPage) error {
 	input := page.Status.(*objectPageStatus)
 
-	listInput := &s3.ListObjectsV2Input{
-		Bucket:            &s.name,
-		Delimiter:         &input.delimiter,
-		MaxKeys:           int32(input.maxKeys),
-		ContinuationToken: input.getServiceContinuationToken(),
-		Prefix:            &input.prefix,
-	}
-	if input.expectedBucketOwner != "" {
-		listInput.ExpectedBucketOwner = &input.expectedBucketOwner
-	}
-	output, err := s.service.ListObjectsV2(ctx, listInput)
+	contents, prefixes, truncated, err := s.listObjects(ctx, input)
 	if err != nil {
 		return err
 	}
 
-	for _, v := range output.CommonPrefixes {
+	for _, v := range prefixes {
+		prefix, err := decodeKey(*v.Prefix, input.encodingType)
+		if err != nil {
+			return err
+		}
+
 		o := s.newObject(true)
-		o.ID = *v.Prefix
-		o.Path = s.getRelPath(*v.Prefix)
+		o.ID = prefix
+		o.Path = s.getRelPath(prefix)
 		o.Mode |= ModeDir
 
 		page.Data = append(page.Data, o)
 	}
 
-	for _, v := range output.Contents {
-		o, err := s.formatFileObject(v)
+	files := make([]*Object, 0, len(contents))
+	for _, v := range contents {
+		key, err := decodeKey(*v.Key, input.encodingType)
 		if err != nil {
 			return err
 		}
+		v.Key = &key
 
-		page.Data = append(page.Data, o)
+		o, err := s.formatFileObject(v, input)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, o)
+	}
+	if input.resolveMode {
+		err = s.resolveObjectModes(ctx, contents, files, input)
+		if err != nil {
+			return err
+		}
 	}
+	page.Data = append(page.Data, files...)
 
-	if !output.IsTruncated {
+	if !truncated {
 		return IterateDone
 	}
-
-	input.continuationToken = *output.NextContinuationToken
 	return nil
 }
 
 func (s *Storage) nextObjectPageByPrefix(ctx context.Context, page *ObjectPage) error {
 	input := page.Status.(*objectPageStatus)
 
-	listInput := &s3.ListObjectsV2Input{
-		Bucket:            &s.name,
-		MaxKeys:           int32(input.maxKeys),
-		ContinuationToken: input.getServiceContinuationToken(),
-		Prefix:            &input.prefix,
-	}
-	if input.expectedBucketOwner != "" {
-		listInput.ExpectedBucketOwner = &input.expectedBucketOwner
-	}
-	output, err := s.service.ListObjectsV2(ctx, listInput)
+	contents, _, truncated, err := s.listObjects(ctx, input)
 	if err != nil {
 		return err
 	}
 
-	for _, v := range output.Contents {
-		o, err := s.formatFileObject(v)
+	files := make([]*Object, 0, len(contents))
+	for _, v := range contents {
+		key, err := decodeKey(*v.Key, input.encodingType)
 		if err != nil {
 			return err
 		}
+		v.Key = &key
 
-		page.Data = append(page.Data, o)
+		o, err := s.formatFileObject(v, input)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, o)
 	}
-	if !output.IsTruncated {
+	if input.resolveMode {
+		err = s.resolveObjectModes(ctx, contents, files, input)
+		if err != nil {
+			return err
+		}
+	}
+	page.Data = append(page.Data, files...)
+	if !truncated {
 		return IterateDone
 	}
-	input.continuationToken = aws.ToString(output.NextContinuationToken)
 	return nil
 }
 
@@ -401,8 +495,8 @@ func (s *Storage) nextPartObjectPageByPrefix(ctx context.Context, page *ObjectPa
 	if !output.IsTruncated {
 		return IterateDone
 	}
-	input.keyMarker = aws.ToString(output.KeyMarker)
-	input.uploadIdMarker = aws.ToString(output.UploadIdMarker)
+	input.keyMarker = aws.ToString(output.NextKeyMarker)
+	input.uploadIdMarker = aws.ToString(output.NextUploadIdMarker)
 	return nil
 }
 
@@ -476,9 +570,12 @@ func (s *Storage) querySignHTTPRead(ctx context.Context, path string, expire tim
 	if err != nil {
 		return
 	}
-	presignClient := s3.NewPresignClient(s.service, func(options *s3.PresignOptions) {
-		options.Expires = expire
-	})
+	key, err := s.resolveLinkKey(ctx, *input.Key, pairs.FollowLinks, pairs.HasFollowLinksMaxDepth, pairs.FollowLinksMaxDepth, pairs.ExceptedBucketOwner)
+	if err != nil {
+		return nil, err
+	}
+	input.Key = &key
+	presignClient := s.newPresignClient(expire)
 	getReq, err := presignClient.PresignGetObject(ctx, input)
 	if err != nil {
 		return
@@ -491,6 +588,35 @@ func (s *Storage) querySignHTTPRead(ctx context.Context, path string, expire tim
 	return
 }
 
+func (s *Storage) querySignHTTPStat(ctx context.Context, path string, expire time.Duration, opt pairStorageStat) (req *http.Request, err error) {
+	if opt.HasMultipartID {
+		// Currently presign only support Get/Put/Head object & UploadPart
+		// We don't support stat multipart via presign for now
+		return nil, services.PairUnsupportedError{Pair: ps.WithMultipartID(opt.MultipartID)}
+	}
+
+	input, err := s.formatHeadObjectInput(path, opt)
+	if err != nil {
+		return
+	}
+	key, err := s.resolveLinkKey(ctx, *input.Key, opt.FollowLinks, opt.HasFollowLinksMaxDepth, opt.FollowLinksMaxDepth, opt.ExceptedBucketOwner)
+	if err != nil {
+		return nil, err
+	}
+	input.Key = &key
+	presignClient := s.newPresignClient(expire)
+	headReq, err := presignClient.PresignHeadObject(ctx, input)
+	if err != nil {
+		return
+	}
+	req, err = http.NewRequest("HEAD", headReq.URL, nil)
+	if err != nil {
+		return
+	}
+	req.Header = headReq.SignedHeader
+	return
+}
+
 func (s *Storage) querySignHTTPWrite(ctx context.Context, path string, size int64, expire time.Duration, opt pairStorageQuerySignHTTPWrite) (req *http.Request, err error) {
 	pairs, err := s.parsePairStorageWrite(opt.pairs)
 	if err != nil {
@@ -501,9 +627,7 @@ func (s *Storage) querySignHTTPWrite(ctx context.Context, path string, size int6
 	if err != nil {
 		return nil, err
 	}
-	presignClient := s3.NewPresignClient(s.service, func(options *s3.PresignOptions) {
-		options.Expires = expire
-	})
+	presignClient := s.newPresignClient(expire)
 	putReq, err := presignClient.PresignPutObject(ctx, input)
 	if err != nil {
 		return nil, err
@@ -528,9 +652,7 @@ func (s *Storage) querySignHTTPWriteMultipart(ctx context.Context, o *Object, si
 		return nil, err
 	}
 
-	presignClient := s3.NewPresignClient(s.service, func(options *s3.PresignOptions) {
-		options.Expires = expire
-	})
+	presignClient := s.newPresignClient(expire)
 	putReq, err := presignClient.PresignUploadPart(ctx, input)
 	if err != nil {
 		return nil, err
@@ -549,12 +671,21 @@ func (s *Storage) read(ctx context.Context, path string, w io.Writer, opt pairSt
 	if err != nil {
 		return
 	}
+	key, err := s.resolveLinkKey(ctx, *input.Key, opt.FollowLinks, opt.HasFollowLinksMaxDepth, opt.FollowLinksMaxDepth, opt.ExceptedBucketOwner)
+	if err != nil {
+		return 0, err
+	}
+	input.Key = &key
 	output, err := s.service.GetObject(ctx, input)
 	if err != nil {
 		return
 	}
 	defer output.Body.Close()
 
+	if opt.HasUserMetadataCallback {
+		opt.UserMetadataCallback(formatUserMetadata(output.Metadata))
+	}
+
 	rc := output.Body
 	if opt.HasIoCallback {
 		rc = iowrap.CallbackReadCloser(rc, opt.IoCallback)
@@ -588,36 +719,33 @@ func (s *Storage) stat(ctx context.Context, path string, opt pairStorageStat) (o
 		return o, nil
 	}
 
-	if opt.HasObjectMode && opt.ObjectMode.IsDir() {
-		if !s.features.VirtualDir {
-			err = services.PairUnsupportedError{Pair: ps.WithObjectMode(opt.ObjectMode)}
-			return
-		}
-
-		rp += "/"
-	}
-
-	input := &s3.HeadObjectInput{
-		Bucket: aws.String(s.name),
-		Key:    aws.String(rp),
-	}
-	if opt.HasExceptedBucketOwner {
-		input.ExpectedBucketOwner = &opt.ExceptedBucketOwner
+	input, err := s.formatHeadObjectInput(path, opt)
+	if err != nil {
+		return
 	}
-	if opt.HasServerSideEncryptionCustomerAlgorithm {
-		input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5, err = calculateEncryptionHeaders(opt.ServerSideEncryptionCustomerAlgorithm, opt.ServerSideEncryptionCustomerKey)
-		if err != nil {
-			return
-		}
+	key, err := s.resolveLinkKey(ctx, *input.Key, opt.FollowLinks, opt.HasFollowLinksMaxDepth, opt.FollowLinksMaxDepth, opt.ExceptedBucketOwner)
+	if err != nil {
+		return nil, err
 	}
+	input.Key = &key
 
 	output, err := s.service.HeadObject(ctx, input)
 	if err != nil {
+		// The dir could exist implicitly without marker object while it has children.
+		if opt.HasObjectMode && opt.ObjectMode.IsDir() && errors.Is(formatError(err), services.ErrObjectNotExist) {
+			o, exist, lerr := s.statImplicitDir(ctx, path, *input.Key, opt)
+			if lerr != nil {
+				return nil, lerr
+			}
+			if exist {
+				return o, nil
+			}
+		}
 		return nil, err
 	}
 
 	o = s.newObject(true)
-	o.ID = rp
+	o.ID = *input.Key
 	o.Path = path
 
 	if output.Metadata != nil {
@@ -631,9 +759,12 @@ func (s *Storage) stat(ctx context.Context, path string, opt pairStorageStat) (o
 				o.Mode |= ModeLink
 				// s3 does not have an absolute path, so when we call `getAbsPath`, it will remove the prefix `/`.
 				// To ensure that the path matches the one the user gets, we should re-add `/` here.
-				o.SetLinkTarget("/" + target)
+				o.SetLinkTarget("/" + resolveLinkTarget(*input.Key, target))
 			}
 		}
+		if um := formatUserMetadata(metadata); len(um) > 0 {
+			o.SetUserMetadata(um)
+		}
 	}
 
 	if o.Mode&ModeLink == 0 && o.Mode&ModeRead == 0 {
@@ -655,6 +786,38 @@ func (s *Storage) stat(ctx context.Context, path string, opt pairStorageStat) (o
 	var sm ObjectSystemMetadata
 	//output.StorageClass's type is s3types.StorageClass, which is equivalent to string
 	sm.StorageClass = string(output.StorageClass)
+	if v := aws.ToString(output.CacheControl); v != "" {
+		sm.CacheControl = v
+	}
+	if v := aws.ToString(output.ContentDisposition); v != "" {
+		sm.ContentDisposition = v
+	}
+	if v := aws.ToString(output.ContentEncoding); v != "" {
+		sm.ContentEncoding = v
+	}
+	if v := aws.ToString(output.ContentLanguage); v != "" {
+		sm.ContentLanguage = v
+	}
+	if output.Expires != nil {
+		sm.Expires = *output.Expires
+	}
+	sm.ObjectLockMode = string(output.ObjectLockMode)
+	if output.ObjectLockRetainUntilDate != nil {
+		sm.ObjectLockRetainUntilDate = *output.ObjectLockRetainUntilDate
+	}
+	sm.ObjectLockLegalHold = output.ObjectLockLegalHoldStatus == s3types.ObjectLockLegalHoldStatusOn
+	if v := aws.ToString(output.Restore); v != "" {
+		if ongoing, expiryDate, err := parseRestore(v); err == nil {
+			sm.RestoreOngoing = ongoing
+			sm.RestoreExpiryDate = expiryDate
+		}
+	}
+	// HeadObjectOutput doesn't contain TagCount, so we have to read it from the raw response.
+	if resp, ok := awsmiddleware.GetRawResponse(output.ResultMetadata).(*smithyhttp.Response); ok {
+		if v, err := strconv.ParseInt(resp.Header.Get("x-amz-tagging-count"), 10, 32); err == nil {
+			sm.TagCount = int32(v)
+		}
+	}
 	if output.ServerSideEncryption != "" {
 		sm.ServerSideEncryption = "output.ServerSideEncryption"
 	}
@@ -699,8 +862,13 @@ func (s *Storage) write(ctx context.Context, path string, r io.Reader, size int6
 		return
 	}
 
+	optFns, err := formatConditionalWriteOptions(opt.HasIfMatch, opt.IfMatch, opt.HasIfNoneMatch, opt.IfNoneMatch)
+	if err != nil {
+		return
+	}
+
 	input.Body = r
-	_, err = s.service.PutObject(ctx, input)
+	_, err = s.service.PutObject(ctx, input, optFns...)
 	if err != nil {
 		return
 	}
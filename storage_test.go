@@ -0,0 +1,2045 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+	signerv4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/beyondstorage/go-service-s3/v2/s3test"
+	ps "github.com/beyondstorage/go-storage/v4/pairs"
+	"github.com/beyondstorage/go-storage/v4/services"
+	"github.com/beyondstorage/go-storage/v4/types"
+)
+
+const (
+	testAccessKey = "access_key"
+	testSecretKey = "secret_key"
+	testLocation  = "us-east-1"
+)
+
+// verifyPresignedRequest will re-calculate the signature of a presigned request with the host it was received on.
+func verifyPresignedRequest(r *http.Request) bool {
+	query := r.URL.Query()
+	signature := query.Get("X-Amz-Signature")
+	query.Del("X-Amz-Signature")
+
+	signingTime, err := time.Parse("20060102T150405Z", query.Get("X-Amz-Date"))
+	if err != nil {
+		return false
+	}
+
+	req, err := http.NewRequest(r.Method, "http://"+r.Host+r.URL.EscapedPath()+"?"+query.Encode(), nil)
+	if err != nil {
+		return false
+	}
+	for _, k := range strings.Split(query.Get("X-Amz-SignedHeaders"), ";") {
+		if k == "host" {
+			continue
+		}
+		req.Header.Set(k, r.Header.Get(k))
+	}
+
+	signedURI, _, err := signerv4.NewSigner().PresignHTTP(context.Background(),
+		aws.Credentials{AccessKeyID: testAccessKey, SecretAccessKey: testSecretKey},
+		req, "UNSIGNED-PAYLOAD", "s3", testLocation, signingTime,
+		func(o *signerv4.SignerOptions) {
+			o.DisableURIPathEscaping = true
+		})
+	if err != nil {
+		return false
+	}
+	u, err := url.Parse(signedURI)
+	if err != nil {
+		return false
+	}
+	return u.Query().Get("X-Amz-Signature") == signature
+}
+
+func TestQuerySignEndpoint(t *testing.T) {
+	var gotHost string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost = r.Host
+		if !verifyPresignedRequest(r) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, store, err := newServicerAndStorager(
+		ps.WithCredential("hmac:"+testAccessKey+":"+testSecretKey),
+		ps.WithLocation(testLocation),
+		ps.WithName("bucket"),
+		WithQuerySignEndpoint("http:"+u.Host),
+	)
+	if err != nil {
+		t.Fatalf("new storager: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		method string
+		sign   func() (*http.Request, error)
+	}{
+		{"read", http.MethodGet, func() (*http.Request, error) {
+			return store.QuerySignHTTPRead("hello.txt", time.Minute,
+				WithResponseContentDisposition(`attachment; filename="hello.txt"`))
+		}},
+		{"stat", http.MethodHead, func() (*http.Request, error) {
+			return store.QuerySignHTTPStat("hello.txt", time.Minute)
+		}},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := tt.sign()
+			if err != nil {
+				t.Fatalf("query sign: %v", err)
+			}
+			if req.Method != tt.method {
+				t.Errorf("method: expected %s, actual %s", tt.method, req.Method)
+			}
+			if req.URL.Host != u.Host {
+				t.Errorf("url host: expected %s, actual %s", u.Host, req.URL.Host)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("send request: %v", err)
+			}
+			resp.Body.Close()
+
+			if gotHost != u.Host {
+				t.Errorf("received host: expected %s, actual %s", u.Host, gotHost)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("signature doesn't match the host client sent, status %d", resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestQuerySignHTTPStatDir(t *testing.T) {
+	_, store, err := newServicerAndStorager(
+		ps.WithCredential("hmac:"+testAccessKey+":"+testSecretKey),
+		ps.WithLocation(testLocation),
+		ps.WithName("bucket"),
+	)
+	if err != nil {
+		t.Fatalf("new storager: %v", err)
+	}
+
+	_, err = store.QuerySignHTTPStat("dir", time.Minute, ps.WithObjectMode(types.ModeDir))
+	if err == nil {
+		t.Errorf("stat dir without virtual_dir should fail")
+	}
+}
+
+func TestTagging(t *testing.T) {
+	srv := newFakeServer(t, "bucket")
+	// s3test doesn't store tags, so the tag count is returned by the handler.
+	rr := &requestRecorder{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.Header().Set("X-Amz-Tagging-Count", "2")
+		}
+		srv.ServeHTTP(w, r)
+	})}
+	store := newTestStorage(t, rr)
+
+	tags := map[string]string{"project": "blue sky", "team": "a&b"}
+	expected := "project=blue+sky&team=a%26b"
+	if _, err := store.Write("a", strings.NewReader("a"), 1, WithTagging(tags)); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if v := rr.requestsOf(http.MethodPut)[0].Header.Get("X-Amz-Tagging"); v != expected {
+		t.Errorf("write x-amz-tagging: expected %s, actual %s", expected, v)
+	}
+
+	rr.reset()
+	if _, err := store.CreateMultipart("b", WithTagging(tags)); err != nil {
+		t.Fatalf("create multipart: %v", err)
+	}
+	if v := rr.requestsOf(http.MethodPost)[0].Header.Get("X-Amz-Tagging"); v != expected {
+		t.Errorf("create multipart x-amz-tagging: expected %s, actual %s", expected, v)
+	}
+
+	o, err := store.Stat("a")
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if v := GetObjectSystemMetadata(o).TagCount; v != 2 {
+		t.Errorf("tag count: expected 2, actual %d", v)
+	}
+}
+
+func TestConditionalRead(t *testing.T) {
+	rr := &requestRecorder{Handler: newFakeServer(t, "bucket")}
+	store := newTestStorage(t, rr)
+	writeObjects(t, store, map[string]string{"a": "hello"})
+	o, err := store.Stat("a")
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	etag := o.MustGetEtag()
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+
+	cases := []struct {
+		name   string
+		pair   types.Pair
+		header string
+		value  string
+		err    error
+	}{
+		{"if match", WithIfMatch(etag), "If-Match", etag, nil},
+		{"if match mismatch", WithIfMatch(`"mismatch"`), "If-Match", `"mismatch"`, ErrPreconditionFailed},
+		{"if none match", WithIfNoneMatch(etag), "If-None-Match", etag, ErrObjectNotModified},
+		{"if none match mismatch", WithIfNoneMatch(`"mismatch"`), "If-None-Match", `"mismatch"`, nil},
+		{"if modified since", WithIfModifiedSince(future), "If-Modified-Since", future.UTC().Format(http.TimeFormat), ErrObjectNotModified},
+		{"if unmodified since", WithIfUnmodifiedSince(past), "If-Unmodified-Since", past.UTC().Format(http.TimeFormat), ErrPreconditionFailed},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			expectRequest := func(op, method string, err error) {
+				t.Helper()
+				if !errors.Is(err, tt.err) {
+					t.Errorf("%s: expected %v, actual %v", op, tt.err, err)
+				}
+				requests := rr.requestsOf(method)
+				if len(requests) != 1 {
+					t.Fatalf("%s: expected 1 request, actual %d", op, len(requests))
+				}
+				if v := requests[0].Header.Get(tt.header); v != tt.value {
+					t.Errorf("%s %s: expected %s, actual %s", op, tt.header, tt.value, v)
+				}
+			}
+
+			rr.reset()
+			var buf bytes.Buffer
+			_, err := store.Read("a", &buf, tt.pair)
+			expectRequest("read", http.MethodGet, err)
+
+			rr.reset()
+			_, err = store.Stat("a", tt.pair)
+			expectRequest("stat", http.MethodHead, err)
+		})
+	}
+}
+
+// writeConcurrently will write to path with n writers at the same time, and return the number of succeeded writers.
+func writeConcurrently(t *testing.T, store *Storage, path string, n int, pairs ...types.Pair) int {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		succeed int
+		// Contents must differ from the existing object, or the ETag still matches after the winner writes.
+		round = time.Now().UnixNano()
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			content := []byte(fmt.Sprintf("writer %d of round %d", i, round))
+			_, err := store.Write(path, bytes.NewReader(content), int64(len(content)), pairs...)
+			if err != nil {
+				if !errors.Is(err, ErrPreconditionFailed) {
+					t.Errorf("write: expected %v, actual %v", ErrPreconditionFailed, err)
+				}
+				return
+			}
+
+			mu.Lock()
+			succeed++
+			mu.Unlock()
+		}(i)
+	}
+	wg.Wait()
+	return succeed
+}
+
+func TestConditionalWrite(t *testing.T) {
+	rr := &requestRecorder{Handler: newFakeServer(t, "bucket")}
+	store := newTestStorage(t, rr)
+
+	content := []byte("hello")
+	write := func(pairs ...types.Pair) error {
+		_, err := store.Write("lease", bytes.NewReader(content), int64(len(content)), pairs...)
+		return err
+	}
+	// lastHeader will return the header of the last request of method.
+	lastHeader := func(method string) http.Header {
+		requests := rr.requestsOf(method)
+		if len(requests) == 0 {
+			t.Fatalf("no %s request received", method)
+		}
+		return requests[len(requests)-1].Header
+	}
+
+	if err := write(WithIfNoneMatch("*")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if v := lastHeader(http.MethodPut).Get("If-None-Match"); v != "*" {
+		t.Errorf("put If-None-Match: expected *, actual %q", v)
+	}
+	if err := write(WithIfNoneMatch("*")); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("write: expected %v, actual %v", ErrPreconditionFailed, err)
+	}
+
+	o, err := store.Stat("lease")
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if err = write(WithIfMatch(o.MustGetEtag())); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if v := lastHeader(http.MethodPut).Get("If-Match"); v != o.MustGetEtag() {
+		t.Errorf("put If-Match: expected %s, actual %q", o.MustGetEtag(), v)
+	}
+	if err = write(WithIfMatch(`"mismatch"`)); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("write: expected %v, actual %v", ErrPreconditionFailed, err)
+	}
+
+	// S3 only supports "*" for If-None-Match of writes, the request should not be sent.
+	rr.reset()
+	if err = write(WithIfNoneMatch(o.MustGetEtag())); !errors.Is(err, services.ErrCapabilityInsufficient) {
+		t.Errorf("write: expected %v, actual %v", services.ErrCapabilityInsufficient, err)
+	}
+	if requests := rr.requestsOf(http.MethodPut); len(requests) != 0 {
+		t.Errorf("write: expected no request, actual %d", len(requests))
+	}
+
+	completeMultipart := func(pairs ...types.Pair) error {
+		mo, err := store.CreateMultipart("multipart")
+		if err != nil {
+			t.Fatalf("create multipart: %v", err)
+		}
+		_, part, err := store.WriteMultipart(mo, bytes.NewReader(content), int64(len(content)), 1)
+		if err != nil {
+			t.Fatalf("write multipart: %v", err)
+		}
+		return store.CompleteMultipart(mo, []*types.Part{part}, pairs...)
+	}
+
+	if err = completeMultipart(WithIfNoneMatch("*")); err != nil {
+		t.Fatalf("complete multipart: %v", err)
+	}
+	if v := lastHeader(http.MethodPost).Get("If-None-Match"); v != "*" {
+		t.Errorf("complete multipart If-None-Match: expected *, actual %q", v)
+	}
+
+	mo, err := store.Stat("multipart")
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if err = completeMultipart(WithIfMatch(mo.MustGetEtag())); err != nil {
+		t.Fatalf("complete multipart: %v", err)
+	}
+	if v := lastHeader(http.MethodPost).Get("If-Match"); v != mo.MustGetEtag() {
+		t.Errorf("complete multipart If-Match: expected %s, actual %q", mo.MustGetEtag(), v)
+	}
+	if err = completeMultipart(WithIfNoneMatch("*")); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("complete multipart: expected %v, actual %v", ErrPreconditionFailed, err)
+	}
+	// The ETag of lease doesn't match multipart.
+	if err = completeMultipart(WithIfMatch(o.MustGetEtag())); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("complete multipart: expected %v, actual %v", ErrPreconditionFailed, err)
+	}
+
+	if mo, err = store.CreateMultipart("multipart"); err != nil {
+		t.Fatalf("create multipart: %v", err)
+	}
+	err = store.CompleteMultipart(mo, nil, WithIfNoneMatch(o.MustGetEtag()))
+	if !errors.Is(err, services.ErrCapabilityInsufficient) {
+		t.Errorf("complete multipart: expected %v, actual %v", services.ErrCapabilityInsufficient, err)
+	}
+
+	t.Run("create only if absent", func(t *testing.T) {
+		if n := writeConcurrently(t, store, "race", 10, WithIfNoneMatch("*")); n != 1 {
+			t.Errorf("expected only 1 writer to win, actual %d", n)
+		}
+	})
+
+	t.Run("compare and swap", func(t *testing.T) {
+		o, err := store.Stat("race")
+		if err != nil {
+			t.Fatalf("stat: %v", err)
+		}
+		if n := writeConcurrently(t, store, "race", 10, WithIfMatch(o.MustGetEtag())); n != 1 {
+			t.Errorf("expected only 1 writer to win, actual %d", n)
+		}
+	})
+}
+
+// aclServer is a fake s3 server which only supports GetObjectAcl and PutObjectAcl, as s3test doesn't support them.
+type aclServer struct {
+	// policy is the AccessControlPolicy returned by GetObjectAcl.
+	policy string
+	// body is the request body of PutObjectAcl received.
+	body string
+}
+
+func (as *aclServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, ok := r.URL.Query()["acl"]; !ok {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = io.WriteString(w, as.policy)
+	case http.MethodPut:
+		content, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		as.body = string(content)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func TestObjectACL(t *testing.T) {
+	as := &aclServer{policy: `<?xml version="1.0" encoding="UTF-8"?>
+<AccessControlPolicy xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+<Owner><ID>owner</ID><DisplayName>owner-name</DisplayName></Owner>
+<AccessControlList>
+<Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="CanonicalUser"><ID>owner</ID><DisplayName>owner-name</DisplayName></Grantee><Permission>FULL_CONTROL</Permission></Grant>
+<Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="Group"><URI>http://acs.amazonaws.com/groups/global/AllUsers</URI></Grantee><Permission>READ</Permission></Grant>
+</AccessControlList>
+</AccessControlPolicy>`}
+	store := newTestStorage(t, as)
+
+	acl, err := store.GetObjectACL("a")
+	if err != nil {
+		t.Fatalf("get object acl: %v", err)
+	}
+	expected := []Grant{
+		{GranteeType: GranteeTypeCanonicalUser, GranteeID: "owner", GranteeDisplayName: "owner-name", Permission: PermissionFullControl},
+		{GranteeType: GranteeTypeGroup, GranteeURI: "http://acs.amazonaws.com/groups/global/AllUsers", Permission: PermissionRead},
+	}
+	if acl.OwnerID != "owner" || acl.OwnerDisplayName != "owner-name" || fmt.Sprint(acl.Grants) != fmt.Sprint(expected) {
+		t.Errorf("acl: expected owner %v, actual %+v", expected, acl)
+	}
+
+	acl.Grants = append(acl.Grants,
+		Grant{GranteeType: GranteeTypeAmazonCustomerByEmail, GranteeEmailAddress: "user@example.com", Permission: PermissionReadAcp})
+	if err = store.PutObjectACL("a", acl); err != nil {
+		t.Fatalf("put object acl: %v", err)
+	}
+	for _, v := range []string{
+		"<Owner><DisplayName>owner-name</DisplayName><ID>owner</ID></Owner>",
+		`xsi:type="CanonicalUser"><ID>owner</ID></Grantee><Permission>FULL_CONTROL</Permission>`,
+		`xsi:type="Group"><URI>http://acs.amazonaws.com/groups/global/AllUsers</URI></Grantee><Permission>READ</Permission>`,
+		`xsi:type="AmazonCustomerByEmail"><EmailAddress>user@example.com</EmailAddress></Grantee><Permission>READ_ACP</Permission>`,
+	} {
+		if !strings.Contains(as.body, v) {
+			t.Errorf("put object acl: %s not found in request body %s", v, as.body)
+		}
+	}
+}
+
+func TestACLPairs(t *testing.T) {
+	rr := &requestRecorder{Handler: newFakeServer(t, "bucket")}
+	store := newTestStorage(t, rr, WithEnableVirtualDir())
+
+	pairs := []types.Pair{
+		WithACL(string(ObjectCannedACLPublicRead)),
+		WithGrantFullControl(`id="owner"`),
+		WithGrantRead(`uri="http://acs.amazonaws.com/groups/global/AllUsers"`),
+		WithGrantReadAcp(`emailAddress="user@example.com"`),
+		WithGrantWriteAcp(`id="writer"`),
+	}
+	expected := map[string]string{
+		"X-Amz-Acl":                "public-read",
+		"X-Amz-Grant-Full-Control": `id="owner"`,
+		"X-Amz-Grant-Read":         `uri="http://acs.amazonaws.com/groups/global/AllUsers"`,
+		"X-Amz-Grant-Read-Acp":     `emailAddress="user@example.com"`,
+		"X-Amz-Grant-Write-Acp":    `id="writer"`,
+	}
+	expectHeader := func(op string, h http.Header) {
+		t.Helper()
+		for k, v := range expected {
+			if h.Get(k) != v {
+				t.Errorf("%s %s: expected %s, actual %q", op, k, v, h.Get(k))
+			}
+		}
+	}
+
+	if _, err := store.Write("a", strings.NewReader("a"), 1, pairs...); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	expectHeader("write", rr.requestsOf(http.MethodPut)[0].Header)
+
+	rr.reset()
+	if _, err := store.CreateMultipart("b", pairs...); err != nil {
+		t.Fatalf("create multipart: %v", err)
+	}
+	expectHeader("create multipart", rr.requestsOf(http.MethodPost)[0].Header)
+
+	rr.reset()
+	if _, err := store.CreateDir("c", pairs...); err != nil {
+		t.Fatalf("create dir: %v", err)
+	}
+	expectHeader("create dir", rr.requestsOf(http.MethodPut)[0].Header)
+
+	req, err := store.QuerySignHTTPWrite("d", 1, time.Minute, pairs...)
+	if err != nil {
+		t.Fatalf("query sign http write: %v", err)
+	}
+	// The signer could hoist some headers into the query, others are signed and must be sent by the client.
+	signed := req.URL.Query().Get("X-Amz-SignedHeaders")
+	for k, v := range expected {
+		if req.URL.Query().Get(k) == v {
+			continue
+		}
+		if req.Header.Get(k) != v || !strings.Contains(signed, strings.ToLower(k)) {
+			t.Errorf("query sign http write %s: expected %s in query or signed headers, actual %s", k, v, req.URL)
+		}
+	}
+}
+
+// lockServer is a fake s3 server which implements Object Lock operations on top of the s3test server,
+// as s3test doesn't support them. Deletes will always be blocked by Object Lock.
+type lockServer struct {
+	*s3test.Server
+
+	// retention and legalHold are returned by GetObjectRetention and GetObjectLegalHold.
+	retention string
+	legalHold string
+	// head is the headers added to the responses of HeadObject.
+	head http.Header
+	// body and header are of the last PutObjectRetention or PutObjectLegalHold received.
+	body   string
+	header http.Header
+}
+
+func (ls *lockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	_, retention := q["retention"]
+	_, legalHold := q["legal-hold"]
+
+	switch {
+	case retention || legalHold:
+		if r.Method == http.MethodPut {
+			content, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			ls.body, ls.header = string(content), r.Header
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		if retention {
+			_, _ = io.WriteString(w, ls.retention)
+		} else {
+			_, _ = io.WriteString(w, ls.legalHold)
+		}
+	case r.Method == http.MethodDelete:
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, "<Error><Code>AccessDenied</Code><Message>Access Denied because object protected by object lock.</Message></Error>")
+	default:
+		if r.Method == http.MethodHead {
+			for k, v := range ls.head {
+				w.Header()[k] = v
+			}
+		}
+		ls.Server.ServeHTTP(w, r)
+	}
+}
+
+func TestObjectLock(t *testing.T) {
+	retainUntilDate := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	ls := &lockServer{
+		Server:    newFakeServer(t, "bucket"),
+		retention: `<Retention><Mode>GOVERNANCE</Mode><RetainUntilDate>2030-01-01T00:00:00.000Z</RetainUntilDate></Retention>`,
+		legalHold: `<LegalHold><Status>ON</Status></LegalHold>`,
+		head: http.Header{
+			"X-Amz-Object-Lock-Mode":              {"COMPLIANCE"},
+			"X-Amz-Object-Lock-Retain-Until-Date": {"2030-01-01T00:00:00.000Z"},
+			"X-Amz-Object-Lock-Legal-Hold":        {"ON"},
+		},
+	}
+	store := newTestStorage(t, ls)
+	writeObjects(t, store, map[string]string{"a": "a"})
+
+	retention, err := store.GetObjectRetention("a")
+	if err != nil {
+		t.Fatalf("get object retention: %v", err)
+	}
+	if retention.Mode != ObjectLockModeGovernance || !retention.RetainUntilDate.Equal(retainUntilDate) {
+		t.Errorf("retention: expected %s until %s, actual %+v", ObjectLockModeGovernance, retainUntilDate, retention)
+	}
+
+	err = store.PutObjectRetention("a", &ObjectRetention{Mode: ObjectLockModeCompliance, RetainUntilDate: retainUntilDate},
+		WithBypassGovernanceRetention())
+	if err != nil {
+		t.Fatalf("put object retention: %v", err)
+	}
+	if !strings.Contains(ls.body, "<Mode>COMPLIANCE</Mode>") || !strings.Contains(ls.body, "<RetainUntilDate>2030-01-01T00:00:00Z</RetainUntilDate>") {
+		t.Errorf("put object retention: unexpected request body %s", ls.body)
+	}
+	if v := ls.header.Get("X-Amz-Bypass-Governance-Retention"); v != "true" {
+		t.Errorf("put object retention x-amz-bypass-governance-retention: expected true, actual %q", v)
+	}
+
+	on, err := store.GetObjectLegalHold("a")
+	if err != nil || !on {
+		t.Errorf("get object legal hold: expected on, actual %v, %v", on, err)
+	}
+	if err = store.PutObjectLegalHold("a", false); err != nil {
+		t.Fatalf("put object legal hold: %v", err)
+	}
+	if !strings.Contains(ls.body, "<Status>OFF</Status>") {
+		t.Errorf("put object legal hold: unexpected request body %s", ls.body)
+	}
+
+	o, err := store.Stat("a")
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	sm := GetObjectSystemMetadata(o)
+	if sm.ObjectLockMode != "COMPLIANCE" || !sm.ObjectLockRetainUntilDate.Equal(retainUntilDate) || !sm.ObjectLockLegalHold {
+		t.Errorf("stat: unexpected lock state %+v", sm)
+	}
+
+	if err = store.Delete("a"); !errors.Is(err, ErrObjectLocked) {
+		t.Errorf("delete: expected %v, actual %v", ErrObjectLocked, err)
+	}
+}
+
+func TestObjectLockPairs(t *testing.T) {
+	rr := &requestRecorder{Handler: newFakeServer(t, "bucket")}
+	store := newTestStorage(t, rr)
+
+	pairs := []types.Pair{
+		WithObjectLockMode(string(ObjectLockModeGovernance)),
+		WithObjectLockRetainUntilDate(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)),
+		WithObjectLockLegalHold(),
+	}
+	expected := map[string]string{
+		"X-Amz-Object-Lock-Mode":              "GOVERNANCE",
+		"X-Amz-Object-Lock-Retain-Until-Date": "2030-01-01T00:00:00Z",
+		"X-Amz-Object-Lock-Legal-Hold":        "ON",
+	}
+	expectHeader := func(op string, h http.Header) {
+		t.Helper()
+		for k, v := range expected {
+			if h.Get(k) != v {
+				t.Errorf("%s %s: expected %s, actual %q", op, k, v, h.Get(k))
+			}
+		}
+	}
+
+	if _, err := store.Write("a", strings.NewReader("a"), 1, pairs...); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	expectHeader("write", rr.requestsOf(http.MethodPut)[0].Header)
+
+	rr.reset()
+	if _, err := store.CreateMultipart("b", pairs...); err != nil {
+		t.Fatalf("create multipart: %v", err)
+	}
+	expectHeader("create multipart", rr.requestsOf(http.MethodPost)[0].Header)
+}
+
+func TestRestoreObject(t *testing.T) {
+	var (
+		body  string
+		code  string
+		state = map[string]int{
+			"InvalidObjectState":       http.StatusForbidden,
+			"RestoreAlreadyInProgress": http.StatusConflict,
+		}
+	)
+	// s3test doesn't support restore, so RestoreObject is handled here.
+	store := newTestStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.URL.Query()["restore"]; r.Method != http.MethodPost || !ok {
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+		content, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body = string(content)
+		if code == "" {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(state[code])
+		_, _ = fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+	}))
+
+	if err := store.RestoreObject("a", WithRestoreDays(3), WithRestoreTier(string(RestoreTierBulk))); err != nil {
+		t.Fatalf("restore object: %v", err)
+	}
+	for _, v := range []string{"<Days>3</Days>", "<GlacierJobParameters><Tier>Bulk</Tier></GlacierJobParameters>"} {
+		if !strings.Contains(body, v) {
+			t.Errorf("restore object: %s not found in request body %s", v, body)
+		}
+	}
+
+	cases := []struct {
+		code     string
+		expected error
+	}{
+		{"InvalidObjectState", ErrObjectNotArchived},
+		{"RestoreAlreadyInProgress", ErrRestoreInProgress},
+	}
+	for _, tt := range cases {
+		t.Run(tt.code, func(t *testing.T) {
+			code = tt.code
+			err := store.RestoreObject("a", WithRestoreDays(1))
+			// InvalidObjectState means the object is archived for read, but not archived for restore.
+			if !errors.Is(err, tt.expected) || errors.Is(err, ErrObjectArchived) {
+				t.Errorf("restore object: expected %v, actual %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+// selectServer is a fake s3 server which returns the events of SelectObjectContent.
+type selectServer struct {
+	// events is a list of event type and payload pairs.
+	events [][2]string
+	// body is the request body received.
+	body string
+}
+
+func (ss *selectServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, ok := r.URL.Query()["select"]; r.Method != http.MethodPost || !ok {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+	content, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	ss.body = string(content)
+
+	w.WriteHeader(http.StatusOK)
+	encoder := eventstream.NewEncoder()
+	for _, v := range ss.events {
+		msg := eventstream.Message{Payload: []byte(v[1])}
+		msg.Headers.Set(":message-type", eventstream.StringValue("event"))
+		msg.Headers.Set(":event-type", eventstream.StringValue(v[0]))
+		if err := encoder.Encode(w, msg); err != nil {
+			return
+		}
+	}
+}
+
+func TestSelectObjectContent(t *testing.T) {
+	cases := []struct {
+		name   string
+		events [][2]string
+		hasErr bool
+	}{
+		{"completed", [][2]string{
+			{"Records", "a,1\n"},
+			{"Progress", "<Progress><BytesScanned>8</BytesScanned><BytesProcessed>8</BytesProcessed><BytesReturned>4</BytesReturned></Progress>"},
+			{"Records", "b,2\n"},
+			{"Stats", "<Stats><BytesScanned>16</BytesScanned><BytesProcessed>16</BytesProcessed><BytesReturned>8</BytesReturned></Stats>"},
+			{"End", ""},
+		}, false},
+		{"without end event", [][2]string{
+			{"Records", "a,1\n"},
+			{"Records", "b,2\n"},
+		}, true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ss := &selectServer{events: tt.events}
+			srv := httptest.NewServer(ss)
+			defer srv.Close()
+
+			u, err := url.Parse(srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, store, err := newServicerAndStorager(
+				ps.WithCredential("hmac:"+testAccessKey+":"+testSecretKey),
+				ps.WithEndpoint("http:"+u.Host),
+				ps.WithLocation(testLocation),
+				ps.WithName("bucket"),
+				WithForcePathStyle(),
+			)
+			if err != nil {
+				t.Fatalf("new storager: %v", err)
+			}
+
+			var (
+				buf   bytes.Buffer
+				stats []SelectStats
+			)
+			n, err := store.SelectObjectContent("data.csv", "SELECT * FROM S3Object s",
+				&SelectCSVInput{FileHeaderInfo: SelectFileHeaderInfoUse}, &SelectCSVOutput{}, &buf,
+				WithSelectStatsCallback(func(v SelectStats) {
+					stats = append(stats, v)
+				}))
+			if !strings.Contains(ss.body, "<Expression>SELECT * FROM S3Object s</Expression>") {
+				t.Errorf("expression not found in request body: %s", ss.body)
+			}
+			if tt.hasErr {
+				if !errors.Is(err, ErrSelectIncomplete) {
+					t.Errorf("expected %v, actual %v", ErrSelectIncomplete, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("select object content: %v", err)
+			}
+
+			if buf.String() != "a,1\nb,2\n" || n != int64(buf.Len()) {
+				t.Errorf("unexpected records %q, n %d", buf.String(), n)
+			}
+			expected := []SelectStats{
+				{BytesScanned: 8, BytesProcessed: 8, BytesReturned: 4},
+				{BytesScanned: 16, BytesProcessed: 16, BytesReturned: 8, Final: true},
+			}
+			if len(stats) != len(expected) || stats[0] != expected[0] || stats[1] != expected[1] {
+				t.Errorf("stats: expected %v, actual %v", expected, stats)
+			}
+		})
+	}
+}
+
+func newTestStorage(t *testing.T, h http.Handler, pairs ...types.Pair) *Storage {
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newEndpointStorage(t, "http:"+u.Host, pairs...)
+}
+
+// newFakeStorage will create a storage of bucket on a new s3test server.
+func newFakeStorage(t *testing.T, pairs ...types.Pair) *Storage {
+	return newEndpointStorage(t, newFakeServer(t, "bucket").Endpoint(), pairs...)
+}
+
+func newFakeServer(t *testing.T, buckets ...string) *s3test.Server {
+	srv := s3test.NewServer(buckets...)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newEndpointStorage(t *testing.T, endpoint string, pairs ...types.Pair) *Storage {
+	// The first pair takes effect, so pairs could override the defaults.
+	_, store, err := newServicerAndStorager(append(pairs,
+		ps.WithCredential("hmac:"+testAccessKey+":"+testSecretKey),
+		ps.WithEndpoint(endpoint),
+		ps.WithLocation(testLocation),
+		ps.WithName("bucket"),
+		WithForcePathStyle(),
+	)...)
+	if err != nil {
+		t.Fatalf("new storager: %v", err)
+	}
+	return store
+}
+
+// requestRecorder will record the requests served by the handler.
+type requestRecorder struct {
+	http.Handler
+
+	mu       sync.Mutex
+	requests []*http.Request
+}
+
+func (rr *requestRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rr.mu.Lock()
+	rr.requests = append(rr.requests, r.Clone(context.Background()))
+	rr.mu.Unlock()
+
+	rr.Handler.ServeHTTP(w, r)
+}
+
+// requestsOf will return the recorded requests of method.
+func (rr *requestRecorder) requestsOf(method string) []*http.Request {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	var requests []*http.Request
+	for _, r := range rr.requests {
+		if r.Method == method {
+			requests = append(requests, r)
+		}
+	}
+	return requests
+}
+
+func (rr *requestRecorder) reset() {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	rr.requests = nil
+}
+
+// writeObjects will write objects with the content to store.
+func writeObjects(t *testing.T, store *Storage, objects map[string]string, pairs ...types.Pair) {
+	t.Helper()
+
+	for path, content := range objects {
+		if _, err := store.Write(path, strings.NewReader(content), int64(len(content)), pairs...); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+}
+
+func listPaths(t *testing.T, store *Storage, path string, pairs ...types.Pair) []string {
+	it, err := store.List(path, pairs...)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+
+	var paths []string
+	for {
+		o, err := it.Next()
+		if errors.Is(err, types.IterateDone) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("next: %v", err)
+		}
+		paths = append(paths, o.Path)
+	}
+	return paths
+}
+
+func TestListOptions(t *testing.T) {
+	rr := &requestRecorder{Handler: newFakeServer(t, "bucket")}
+	store := newTestStorage(t, rr)
+	writeObjects(t, store, map[string]string{"a": "", "b\x01c": "", "c": "", "d": "", "e": ""})
+
+	paths := listPaths(t, store, "",
+		WithStartAfter("a"), WithMaxKeys(2), WithEncodingType(EncodingTypeURL), WithFetchOwner())
+
+	expected := []string{"b\x01c", "c", "d", "e"}
+	if strings.Join(paths, ",") != strings.Join(expected, ",") {
+		t.Errorf("paths: expected %q, actual %q", expected, paths)
+	}
+	requests := rr.requestsOf(http.MethodGet)
+	if len(requests) != 2 {
+		t.Errorf("expected 2 pages, actual %d", len(requests))
+	}
+	for _, r := range requests {
+		if v := r.URL.Query(); v.Get("max-keys") != "2" || v.Get("start-after") != "a" {
+			t.Errorf("unexpected request query %v", v)
+		}
+	}
+
+	it, err := store.List("", WithMaxKeys(1), WithFetchOwner())
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	o, err := it.Next()
+	if err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	// Listed objects are stat lazily, the owner from list must survive the stat.
+	if _, err = store.Write(o.Path, strings.NewReader(""), 0, ps.WithContentType("text/plain")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if v, _ := o.GetContentType(); v != "text/plain" {
+		t.Errorf("content type: expected text/plain, actual %q", v)
+	}
+	if id := GetObjectSystemMetadata(o).OwnerID; id != "s3test" {
+		t.Errorf("owner id: expected s3test, actual %s", id)
+	}
+
+	_, err = store.List("", WithMaxKeys(1001))
+	if !errors.Is(err, services.ErrRestrictionDissatisfied) {
+		t.Errorf("max keys: expected %v, actual %v", services.ErrRestrictionDissatisfied, err)
+	}
+}
+
+func TestListContinuationToken(t *testing.T) {
+	store := newFakeStorage(t)
+	writeObjects(t, store, map[string]string{"a": "", "b": "", "c": "", "d": "", "e": ""})
+
+	it, err := store.List("", WithMaxKeys(2))
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	// Consume the first page, and save the token of the next page.
+	for i := 0; i < 2; i++ {
+		if _, err := it.Next(); err != nil {
+			t.Fatalf("next: %v", err)
+		}
+	}
+	token := it.ContinuationToken()
+
+	paths := listPaths(t, store, "", WithMaxKeys(2), ps.WithContinuationToken(token))
+	expected := []string{"c", "d", "e"}
+	if strings.Join(paths, ",") != strings.Join(expected, ",") {
+		t.Errorf("paths: expected %q, actual %q", expected, paths)
+	}
+}
+
+func TestListObjectsV1(t *testing.T) {
+	rr := &requestRecorder{Handler: newFakeServer(t, "bucket")}
+	store := newTestStorage(t, rr, WithUseListObjectsV1())
+	writeObjects(t, store, map[string]string{"a": "", "b\x01c": "", "c": "", "d": "", "e": ""})
+
+	paths := listPaths(t, store, "", WithStartAfter("a"), WithMaxKeys(2), WithEncodingType(EncodingTypeURL))
+	expected := []string{"b\x01c", "c", "d", "e"}
+	if strings.Join(paths, ",") != strings.Join(expected, ",") {
+		t.Errorf("paths: expected %q, actual %q", expected, paths)
+	}
+
+	// NextMarker is not returned without delimiter, the last key will be used as the marker.
+	markers := []string{"a", "c"}
+	requests := rr.requestsOf(http.MethodGet)
+	if len(requests) != len(markers) {
+		t.Fatalf("expected %d pages, actual %d", len(markers), len(requests))
+	}
+	for i, r := range requests {
+		if v := r.URL.Query(); v.Get("list-type") != "" || v.Get("marker") != markers[i] {
+			t.Errorf("page %d: expected ListObjects with marker %s, actual %v", i, markers[i], v)
+		}
+	}
+}
+
+func TestListResolveMode(t *testing.T) {
+	rr := &requestRecorder{Handler: newFakeServer(t, "bucket")}
+	store := newTestStorage(t, rr, WithEnableVirtualDir(), WithEnableVirtualLink())
+	writeObjects(t, store, map[string]string{"dir/": "", "empty": "", "file": "0123456789"})
+	if _, err := store.CreateLink("link", "file"); err != nil {
+		t.Fatalf("create link: %v", err)
+	}
+	rr.reset()
+
+	it, err := store.List("", WithResolveMode(), WithConcurrency(2))
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+
+	modes := make(map[string]types.ObjectMode)
+	for {
+		o, err := it.Next()
+		if errors.Is(err, types.IterateDone) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("next: %v", err)
+		}
+		modes[o.Path] = o.Mode
+
+		if o.Path == "link" {
+			if target := o.MustGetLinkTarget(); target != "/file" {
+				t.Errorf("link target: expected /file, actual %s", target)
+			}
+		}
+	}
+
+	expected := map[string]types.ObjectMode{
+		"dir/":  types.ModeDir,
+		"empty": types.ModeRead,
+		"file":  types.ModeRead,
+		"link":  types.ModeLink,
+	}
+	for k, v := range expected {
+		if modes[k] != v {
+			t.Errorf("%s mode: expected %s, actual %s", k, v, modes[k])
+		}
+	}
+
+	var heads []string
+	for _, r := range rr.requestsOf(http.MethodHead) {
+		heads = append(heads, strings.TrimPrefix(r.URL.Path, "/bucket/"))
+	}
+	sort.Strings(heads)
+	if strings.Join(heads, ",") != "empty,link" {
+		t.Errorf("only empty objects should be checked, actual %v", heads)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	store := newFakeStorage(t, WithEnableVirtualDir())
+	writeObjects(t, store, map[string]string{
+		"a/1": "", "a/2": "", "a/b/3": "", "c/": "", "c/4": "", "d": "", "e.log": "", "f/g/5.log": "",
+	})
+
+	walk := func(path string, pairs ...types.Pair) []string {
+		var (
+			mu    sync.Mutex
+			paths []string
+		)
+		err := store.Walk(path, func(o *types.Object) error {
+			mu.Lock()
+			defer mu.Unlock()
+			paths = append(paths, o.Path)
+			return nil
+		}, pairs...)
+		if err != nil {
+			t.Fatalf("walk %s: %v", path, err)
+		}
+		sort.Strings(paths)
+		return paths
+	}
+
+	cases := []struct {
+		name     string
+		path     string
+		pairs    []types.Pair
+		expected []string
+	}{
+		{"all", "", []types.Pair{WithConcurrency(2)}, []string{"a/1", "a/2", "a/b/3", "c/4", "d", "e.log", "f/g/5.log"}},
+		{"dir", "a", nil, []string{"a/1", "a/2", "a/b/3"}},
+		{"include", "", []types.Pair{WithInclude("*.log")}, []string{"e.log", "f/g/5.log"}},
+		{"include path", "", []types.Pair{WithInclude("f/*/*.log")}, []string{"f/g/5.log"}},
+		{"exclude", "", []types.Pair{WithExclude("a"), WithConcurrency(1)}, []string{"c/4", "d", "e.log", "f/g/5.log"}},
+		{"exclude nested dir", "", []types.Pair{WithExclude("g")}, []string{"a/1", "a/2", "a/b/3", "c/4", "d", "e.log"}},
+		{"exclude base name", "", []types.Pair{WithExclude("[0-9]*")}, []string{"d", "e.log"}},
+		{"exclude file", "a", []types.Pair{WithExclude("a/[0-9]")}, []string{"a/b/3"}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			paths := walk(tt.path, tt.pairs...)
+			if strings.Join(paths, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("paths: expected %q, actual %q", tt.expected, paths)
+			}
+		})
+	}
+
+	errStop := errors.New("stop")
+	err := store.Walk("", func(o *types.Object) error {
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("walk: expected %v, actual %v", errStop, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = store.WalkWithContext(ctx, "", func(o *types.Object) error {
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("walk: expected %v, actual %v", context.Canceled, err)
+	}
+
+	err = store.Walk("", func(o *types.Object) error {
+		return nil
+	}, WithInclude("["))
+	if !errors.Is(err, services.ErrRestrictionDissatisfied) {
+		t.Errorf("walk: expected %v, actual %v", services.ErrRestrictionDissatisfied, err)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	rr := &requestRecorder{Handler: newFakeServer(t, "bucket")}
+	store := newTestStorage(t, rr)
+	writeObjects(t, store, map[string]string{"a/1": "1", "a/b/2": "22", "c": "4444", "d/3": "88888888"})
+
+	o, err := store.CreateMultipart("a/b/4")
+	if err != nil {
+		t.Fatalf("create multipart: %v", err)
+	}
+	for i, size := range []int{16, 32} {
+		if _, _, err = store.WriteMultipart(o, bytes.NewReader(make([]byte, size)), int64(size), i+1); err != nil {
+			t.Fatalf("write multipart: %v", err)
+		}
+	}
+	rr.reset()
+
+	summary, err := store.Usage("")
+	if err != nil {
+		t.Fatalf("usage: %v", err)
+	}
+	if summary.Total != (UsageStats{Objects: 4, Bytes: 15}) {
+		t.Errorf("total: expected 4 objects 15 bytes, actual %+v", summary.Total)
+	}
+	if stats := summary.StorageClasses["STANDARD"]; stats != summary.Total {
+		t.Errorf("storage class: expected %+v, actual %+v", summary.Total, stats)
+	}
+	if summary.Multiparts != (UsageStats{Objects: 1, Bytes: 48}) {
+		t.Errorf("multiparts: expected 1 upload 48 bytes, actual %+v", summary.Multiparts)
+	}
+
+	expected := map[string]UsageStats{"": {1, 4}, "a/": {2, 3}, "d/": {1, 8}}
+	if len(summary.Prefixes) != len(expected) {
+		t.Errorf("prefixes: expected %d, actual %d", len(expected), len(summary.Prefixes))
+	}
+	for k, v := range expected {
+		if g, ok := summary.Prefixes[k]; !ok || g.Total != v {
+			t.Errorf("prefix %q: expected %+v, actual %+v", k, v, g)
+		}
+	}
+	if g := summary.Prefixes["a/"]; g == nil || g.Multiparts.Bytes != 48 {
+		t.Errorf("prefix a/ multiparts: expected 48 bytes, actual %+v", g)
+	}
+
+	summary, err = store.Usage("a")
+	if err != nil {
+		t.Fatalf("usage: %v", err)
+	}
+	if g := summary.Prefixes["a/b/"]; g == nil || g.Total.Bytes != 2 || g.Multiparts.Bytes != 48 {
+		t.Errorf("prefix a/b/: expected 2 bytes and 48 multipart bytes, actual %+v", g)
+	}
+	if heads := rr.requestsOf(http.MethodHead); len(heads) != 0 {
+		t.Errorf("usage should not stat objects, actual %d requests", len(heads))
+	}
+}
+
+// transitionServer is a fake s3 server which returns an encrypted object of size for HeadObject,
+// and accepts the requests sent by transition without storing anything.
+type transitionServer struct {
+	size int64
+}
+
+func (ts *transitionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	has := func(k string) bool {
+		_, ok := q[k]
+		return ok
+	}
+	h := w.Header()
+	h.Set("Content-Type", "application/xml")
+
+	switch {
+	case r.Method == http.MethodHead:
+		h.Set("Content-Type", "text/plain")
+		h.Set("Content-Length", strconv.FormatInt(ts.size, 10))
+		h.Set("ETag", `"etag"`)
+		h.Set("X-Amz-Meta-Foo", "bar")
+		h.Set("X-Amz-Server-Side-Encryption", "aws:kms")
+		h.Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", "key-id")
+		h.Set("X-Amz-Server-Side-Encryption-Bucket-Key-Enabled", "true")
+	case r.Method == http.MethodGet && has("tagging"):
+		_, _ = io.WriteString(w, "<Tagging><TagSet><Tag><Key>k</Key><Value>v</Value></Tag></TagSet></Tagging>")
+	case r.Method == http.MethodPost && has("uploads"):
+		_, _ = io.WriteString(w, "<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>a</Key><UploadId>id</UploadId></InitiateMultipartUploadResult>")
+	case r.Method == http.MethodPost:
+		_, _ = io.WriteString(w, `<CompleteMultipartUploadResult><ETag>"done"</ETag></CompleteMultipartUploadResult>`)
+	case r.Method == http.MethodPut && q.Get("partNumber") != "":
+		_, _ = io.WriteString(w, `<CopyPartResult><ETag>"part"</ETag></CopyPartResult>`)
+	case r.Method == http.MethodPut:
+		_, _ = io.WriteString(w, `<CopyObjectResult><ETag>"copy"</ETag></CopyObjectResult>`)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func TestTransitionStorageClass(t *testing.T) {
+	expectHeader := func(op string, h http.Header, expected map[string]string) {
+		t.Helper()
+		for k, v := range expected {
+			if h.Get(k) != v {
+				t.Errorf("%s %s: expected %s, actual %q", op, k, v, h.Get(k))
+			}
+		}
+	}
+	sse := map[string]string{
+		"X-Amz-Storage-Class":                             "STANDARD_IA",
+		"X-Amz-Server-Side-Encryption":                    "aws:kms",
+		"X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id":     "key-id",
+		"X-Amz-Server-Side-Encryption-Bucket-Key-Enabled": "true",
+	}
+
+	t.Run("copy", func(t *testing.T) {
+		rr := &requestRecorder{Handler: &transitionServer{size: 10}}
+		store := newTestStorage(t, rr)
+		if err := store.TransitionStorageClass("a", string(StorageClassStandardIa)); err != nil {
+			t.Fatalf("transition: %v", err)
+		}
+
+		requests := rr.requestsOf(http.MethodPut)
+		if len(requests) != 1 {
+			t.Fatalf("copy object: expected 1 request, actual %d", len(requests))
+		}
+		expectHeader("copy object", requests[0].Header, sse)
+		expectHeader("copy object", requests[0].Header, map[string]string{
+			"X-Amz-Copy-Source":          "bucket%2Fa",
+			"X-Amz-Copy-Source-If-Match": `"etag"`,
+			"X-Amz-Metadata-Directive":   "COPY",
+			"X-Amz-Tagging-Directive":    "COPY",
+		})
+	})
+
+	t.Run("multipart copy", func(t *testing.T) {
+		size := int64(copySizeMaximum + 1)
+		rr := &requestRecorder{Handler: &transitionServer{size: size}}
+		store := newTestStorage(t, rr)
+		if err := store.TransitionStorageClass("a", string(StorageClassStandardIa)); err != nil {
+			t.Fatalf("transition: %v", err)
+		}
+
+		posts := rr.requestsOf(http.MethodPost)
+		if len(posts) != 2 {
+			t.Fatalf("expected create and complete multipart, actual %d requests", len(posts))
+		}
+		// Metadata and tags can't be copied by multipart copy, they should be set on create.
+		expectHeader("create multipart", posts[0].Header, sse)
+		expectHeader("create multipart", posts[0].Header, map[string]string{
+			"Content-Type":   "text/plain",
+			"X-Amz-Meta-Foo": "bar",
+			"X-Amz-Tagging":  "k=v",
+		})
+
+		partSize := calculatePartSize(size, copyPartSize)
+		parts := rr.requestsOf(http.MethodPut)
+		if n := (size + partSize - 1) / partSize; int64(len(parts)) != n {
+			t.Fatalf("upload part copy: expected %d requests, actual %d", n, len(parts))
+		}
+		for i, r := range parts {
+			end := int64(i+1)*partSize - 1
+			if end >= size {
+				end = size - 1
+			}
+			expectHeader("upload part copy", r.Header, map[string]string{
+				"X-Amz-Copy-Source":          "bucket%2Fa",
+				"X-Amz-Copy-Source-If-Match": `"etag"`,
+				"X-Amz-Copy-Source-Range":    fmt.Sprintf("bytes=%d-%d", int64(i)*partSize, end),
+			})
+		}
+	})
+}
+
+func TestTransitionStorageClassBulkCancel(t *testing.T) {
+	srv := newFakeServer(t, "bucket")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := newTestStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Cancel the transition while the first object is being transitioned.
+		if r.Method == http.MethodHead {
+			cancel()
+			<-r.Context().Done()
+			return
+		}
+		srv.ServeHTTP(w, r)
+	}))
+
+	objects := make(map[string]string)
+	for i := 0; i < 10; i++ {
+		objects[strconv.Itoa(i)] = ""
+	}
+	writeObjects(t, store, objects)
+	it, err := store.List("")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+
+	summary, err := store.TransitionStorageClassBulkWithContext(ctx, it, string(StorageClassStandardIa), WithConcurrency(1))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("transition: expected %v, actual %v", context.Canceled, err)
+	}
+	if n := summary.Objects + summary.Skipped + int64(len(summary.Errors)); n >= int64(len(objects)) {
+		t.Errorf("transition should be stopped once canceled, actual %d objects handled", n)
+	}
+}
+
+// copyServer is a fake s3 server which implements CopyObject on top of the s3test server, as s3test doesn't support it.
+type copyServer struct {
+	*s3test.Server
+
+	mu sync.Mutex
+	// deny means CopyObject and UploadPartCopy will always return AccessDenied.
+	deny bool
+	// copies is the count of CopyObject and UploadPartCopy requests succeeded.
+	copies int
+}
+
+func (cs *copyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	source := r.Header.Get("X-Amz-Copy-Source")
+	if source == "" {
+		cs.Server.ServeHTTP(w, r)
+		return
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.deny {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, "<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>")
+		return
+	}
+	source, err := url.PathUnescape(source)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// UploadPartCopy copies the range of the source into a part.
+	isPart := r.URL.Query().Get("uploadId") != ""
+
+	get := httptest.NewRecorder()
+	getReq := httptest.NewRequest(http.MethodGet, "/"+strings.TrimPrefix(source, "/"), nil)
+	if v := r.Header.Get("X-Amz-Copy-Source-Range"); isPart && v != "" {
+		getReq.Header.Set("Range", v)
+	}
+	cs.Server.ServeHTTP(get, getReq)
+	if get.Code != http.StatusOK && get.Code != http.StatusPartialContent {
+		w.WriteHeader(get.Code)
+		_, _ = w.Write(get.Body.Bytes())
+		return
+	}
+
+	put := httptest.NewRequest(http.MethodPut, r.URL.RequestURI(), get.Body)
+	if !isPart {
+		// Only Content-Type and user metadata are copied or replaced.
+		header := get.Header()
+		if r.Header.Get("X-Amz-Metadata-Directive") == "REPLACE" {
+			header = r.Header
+		}
+		for k, v := range header {
+			if k == "Content-Type" || strings.HasPrefix(k, "X-Amz-Meta-") {
+				put.Header[k] = v
+			}
+		}
+	}
+	rec := httptest.NewRecorder()
+	cs.Server.ServeHTTP(rec, put)
+	if rec.Code != http.StatusOK {
+		w.WriteHeader(rec.Code)
+		_, _ = w.Write(rec.Body.Bytes())
+		return
+	}
+	cs.copies++
+
+	w.Header().Set("Content-Type", "application/xml")
+	if isPart {
+		_, _ = fmt.Fprintf(w, "<CopyPartResult><ETag>%s</ETag></CopyPartResult>", rec.Header().Get("ETag"))
+		return
+	}
+	_, _ = fmt.Fprintf(w, "<CopyObjectResult><ETag>%s</ETag></CopyObjectResult>", rec.Header().Get("ETag"))
+}
+
+func TestSync(t *testing.T) {
+	store := newFakeStorage(t)
+	writeObjects(t, store, map[string]string{"dst/extra": "extra"})
+
+	src := t.TempDir()
+	past := time.Now().Add(-time.Hour)
+	for name, content := range map[string]string{"a": "1", "sub/b": "22"} {
+		p := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(p, past, past); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sync := func(upload bool, dir string, pairs ...types.Pair) (*SyncSummary, map[string]string) {
+		events := make(map[string]string)
+		pairs = append(pairs, WithSyncCallback(func(e SyncEvent) {
+			if e.Err != nil {
+				t.Errorf("sync %s: %v", e.Path, e.Err)
+			}
+			events[e.Path] = e.Action
+		}))
+
+		var (
+			summary *SyncSummary
+			err     error
+		)
+		if upload {
+			summary, err = store.SyncUpload(dir, "dst", pairs...)
+		} else {
+			summary, err = store.SyncDownload("dst", dir, pairs...)
+		}
+		if err != nil {
+			t.Fatalf("sync: %v", err)
+		}
+		return summary, events
+	}
+	expectEvents := func(expected, actual map[string]string) {
+		t.Helper()
+		if fmt.Sprint(expected) != fmt.Sprint(actual) {
+			t.Errorf("events: expected %v, actual %v", expected, actual)
+		}
+	}
+
+	summary, events := sync(true, src)
+	expectEvents(map[string]string{"a": SyncActionUpload, "sub/b": SyncActionUpload}, events)
+	if summary.Uploaded != 2 || summary.Bytes != 3 {
+		t.Errorf("summary: expected 2 files 3 bytes uploaded, actual %+v", summary)
+	}
+
+	// Objects are newer than local files now.
+	_, events = sync(true, src)
+	expectEvents(map[string]string{"a": SyncActionSkip, "sub/b": SyncActionSkip}, events)
+
+	_, events = sync(true, src, WithSyncDelete(), WithDryRun())
+	expectEvents(map[string]string{"a": SyncActionSkip, "sub/b": SyncActionSkip, "extra": SyncActionDelete}, events)
+	if _, err := store.Stat("dst/extra"); err != nil {
+		t.Errorf("dry run should not delete objects: %v", err)
+	}
+	sync(true, src, WithSyncDelete())
+	if _, err := store.Stat("dst/extra"); !errors.Is(err, services.ErrObjectNotExist) {
+		t.Errorf("extra should be deleted: %v", err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "dst")
+	summary, events = sync(false, dst)
+	expectEvents(map[string]string{"a": SyncActionDownload, "sub/b": SyncActionDownload}, events)
+	if summary.Downloaded != 2 {
+		t.Errorf("summary: expected 2 files downloaded, actual %+v", summary)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(dst, "sub", "b"))
+	if err != nil || string(content) != "22" {
+		t.Errorf("content: expected 22, actual %q, %v", content, err)
+	}
+
+	// The mtime of downloaded files is the same as objects.
+	_, events = sync(false, dst)
+	expectEvents(map[string]string{"a": SyncActionSkip, "sub/b": SyncActionSkip}, events)
+
+	// Files with the same size and mtime can only be detected by MD5.
+	p := filepath.Join(dst, "a")
+	fi, err := os.Stat(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(p, []byte("3"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chtimes(p, fi.ModTime(), fi.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	_, events = sync(false, dst)
+	expectEvents(map[string]string{"a": SyncActionSkip, "sub/b": SyncActionSkip}, events)
+	_, events = sync(false, dst, WithSyncCompare(SyncCompareMD5))
+	expectEvents(map[string]string{"a": SyncActionDownload, "sub/b": SyncActionSkip}, events)
+
+	_, err = store.SyncDownload("dst", dst, WithSyncCompare("crc"))
+	if !errors.Is(err, services.ErrCapabilityInsufficient) {
+		t.Errorf("sync compare: expected %v, actual %v", services.ErrCapabilityInsufficient, err)
+	}
+}
+
+func TestSyncWriteOptions(t *testing.T) {
+	store := newFakeStorage(t)
+	src := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(src, "a"), []byte("1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	metadata := map[string]string{"foo": "bar"}
+
+	_, err := store.SyncUpload(src, "dst", WithStorageClass(string(StorageClassStandardIa)), WithUserMetadata(metadata))
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	// Files larger than the threshold will be uploaded with multipart, which should have the same options.
+	err = store.writeWithMultipart(context.Background(), "dst/b", strings.NewReader("22"), 2, pairStorageWrite{
+		HasStorageClass: true,
+		StorageClass:    string(StorageClassStandardIa),
+		HasUserMetadata: true,
+		UserMetadata:    metadata,
+	})
+	if err != nil {
+		t.Fatalf("write with multipart: %v", err)
+	}
+
+	for _, path := range []string{"dst/a", "dst/b"} {
+		o, err := store.Stat(path)
+		if err != nil {
+			t.Fatalf("stat %s: %v", path, err)
+		}
+		m, _ := o.GetUserMetadata()
+		if sm := GetObjectSystemMetadata(o); sm.StorageClass != string(StorageClassStandardIa) || m["foo"] != "bar" {
+			t.Errorf("%s: expected %s with metadata, actual %s %v", path, StorageClassStandardIa, sm.StorageClass, m)
+		}
+	}
+}
+
+func TestMirror(t *testing.T) {
+	cs := &copyServer{Server: newFakeServer(t, "bucket", "mirror")}
+	src := newTestStorage(t, cs)
+	dst := newTestStorage(t, cs, ps.WithName("mirror"))
+	writeObjects(t, src, map[string]string{"src/a": "1"},
+		ps.WithContentType("text/plain"), WithUserMetadata(map[string]string{"foo": "bar"}))
+	writeObjects(t, src, map[string]string{"src/b/c": "22"})
+	writeObjects(t, dst, map[string]string{"dst/b/c": "22"})
+
+	summary, err := src.Mirror("src", dst, "dst", WithPreserveMetadata())
+	if err != nil {
+		t.Fatalf("mirror: %v", err)
+	}
+	if summary.Copied != 1 || summary.Skipped != 1 || summary.Bytes != 1 || len(summary.Errors) != 0 {
+		t.Errorf("summary: expected 1 copied and 1 skipped, actual %+v", summary)
+	}
+	o, err := dst.Stat("dst/a")
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if m, _ := o.GetUserMetadata(); m["foo"] != "bar" {
+		t.Errorf("metadata: expected bar, actual %v", m)
+	}
+
+	// Without preserving metadata, the Content-Type should still be kept.
+	if _, err = src.Mirror("src", dst, "plain"); err != nil {
+		t.Fatalf("mirror: %v", err)
+	}
+	if o, err = dst.Stat("plain/a"); err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	m, _ := o.GetUserMetadata()
+	if ct, _ := o.GetContentType(); len(m) != 0 || ct != "text/plain" {
+		t.Errorf("copied object: expected text/plain without metadata, actual %s %v", ct, m)
+	}
+
+	// The source can't be read by the destination, the object should be streamed.
+	cs.deny = true
+	writeObjects(t, src, map[string]string{"src/a": "333"},
+		ps.WithContentType("text/plain"), WithUserMetadata(map[string]string{"foo": "baz"}))
+	summary, err = src.Mirror("src", dst, "dst", WithPreserveMetadata())
+	if err != nil {
+		t.Fatalf("mirror: %v", err)
+	}
+	if summary.Streamed != 1 || summary.Skipped != 1 || summary.Bytes != 3 || len(summary.Errors) != 0 {
+		t.Errorf("summary: expected 1 streamed and 1 skipped, actual %+v", summary)
+	}
+	var buf bytes.Buffer
+	if _, err = dst.Read("dst/a", &buf); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if o, err = dst.Stat("dst/a"); err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	m, _ = o.GetUserMetadata()
+	if buf.String() != "333" || m["foo"] != "baz" || o.MustGetContentType() != "text/plain" {
+		t.Errorf("streamed object: expected 333 with metadata, actual %q %v %s", buf.String(), m, o.MustGetContentType())
+	}
+	if summary, err = src.Mirror("src", dst, "plain"); err != nil || summary.Streamed != 1 {
+		t.Fatalf("mirror: expected 1 streamed, actual %+v %v", summary, err)
+	}
+	if o, err = dst.Stat("plain/a"); err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	m, _ = o.GetUserMetadata()
+	if ct, _ := o.GetContentType(); len(m) != 0 || ct != "text/plain" {
+		t.Errorf("streamed object: expected text/plain without metadata, actual %s %v", ct, m)
+	}
+	if cs.copies != 3 {
+		t.Errorf("copies: expected 3, actual %d", cs.copies)
+	}
+}
+
+func TestMirrorMultipart(t *testing.T) {
+	cs := &copyServer{Server: newFakeServer(t, "bucket", "mirror")}
+	src := newTestStorage(t, cs)
+	dst := newTestStorage(t, cs, ps.WithName("mirror"))
+	writeObjects(t, src, map[string]string{"src/a": "hello"},
+		ps.WithContentType("text/plain"), WithUserMetadata(map[string]string{"foo": "bar"}))
+
+	ctx := context.Background()
+	files, err := src.listSyncFiles(ctx, "src/", "")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+
+	// Objects larger than a single copy or write are copied and streamed by parts, which are
+	// called directly here to avoid writing such objects.
+	cases := []struct {
+		name string
+		fn   func(ctx context.Context, path string, dst *Storage, dstPath string, f syncFile, opt pairStorageMirror) error
+	}{
+		{"copy", src.multipartCopyObjectTo},
+		{"stream", src.multipartStreamObjectTo},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, preserve := range []bool{false, true} {
+				dstPath := fmt.Sprintf("%s/%v", tt.name, preserve)
+				err := tt.fn(ctx, "src/a", dst, dstPath, files["a"], pairStorageMirror{PreserveMetadata: preserve})
+				if err != nil {
+					t.Fatalf("mirror %s: %v", dstPath, err)
+				}
+
+				var buf bytes.Buffer
+				if _, err = dst.Read(dstPath, &buf); err != nil {
+					t.Fatalf("read: %v", err)
+				}
+				o, err := dst.Stat(dstPath)
+				if err != nil {
+					t.Fatalf("stat: %v", err)
+				}
+				m, _ := o.GetUserMetadata()
+				ct, _ := o.GetContentType()
+				if buf.String() != "hello" || ct != "text/plain" || (m["foo"] == "bar") != preserve {
+					t.Errorf("%s: unexpected %q %s %v", dstPath, buf.String(), ct, m)
+				}
+			}
+		})
+	}
+	if cs.copies != 2 {
+		t.Errorf("copies: expected 2 parts copied, actual %d", cs.copies)
+	}
+}
+
+func TestMirrorCancel(t *testing.T) {
+	cs := &copyServer{Server: newFakeServer(t, "bucket", "mirror")}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	src := newTestStorage(t, cs)
+	dst := newTestStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Cancel the mirror while the first object is being copied.
+		if r.Header.Get("X-Amz-Copy-Source") != "" {
+			cancel()
+			<-r.Context().Done()
+			return
+		}
+		cs.ServeHTTP(w, r)
+	}), ps.WithName("mirror"))
+
+	objects := make(map[string]string)
+	for i := 0; i < 10; i++ {
+		objects["src/"+strconv.Itoa(i)] = ""
+	}
+	writeObjects(t, src, objects)
+
+	summary, err := src.MirrorWithContext(ctx, "src", dst, "dst", WithConcurrency(1))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("mirror: expected %v, actual %v", context.Canceled, err)
+	}
+	if n := summary.Copied + summary.Streamed + int64(len(summary.Errors)); n >= int64(len(objects)) {
+		t.Errorf("mirror should be stopped once canceled, actual %d objects handled", n)
+	}
+}
+
+func TestStatSystemMetadata(t *testing.T) {
+	store := newFakeStorage(t)
+	expires := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	writeObjects(t, store, map[string]string{"a": "hello"},
+		WithCacheControl("no-cache"),
+		WithContentDisposition(`attachment; filename="a.txt"`),
+		WithContentEncoding("identity"),
+		WithContentLanguage("en-US"),
+		WithExpires(expires),
+	)
+	writeObjects(t, store, map[string]string{"b": "hello"})
+
+	o, err := store.Stat("a")
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	sm := GetObjectSystemMetadata(o)
+	if sm.CacheControl != "no-cache" || sm.ContentDisposition != `attachment; filename="a.txt"` ||
+		sm.ContentEncoding != "identity" || sm.ContentLanguage != "en-US" || !sm.Expires.Equal(expires) {
+		t.Errorf("system metadata: unexpected %+v", sm)
+	}
+
+	o, err = store.Stat("b")
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if sm = GetObjectSystemMetadata(o); sm.CacheControl != "" || sm.ContentDisposition != "" ||
+		sm.ContentEncoding != "" || sm.ContentLanguage != "" || !sm.Expires.IsZero() {
+		t.Errorf("system metadata: expected empty, actual %+v", sm)
+	}
+}
+
+func TestReadUserMetadata(t *testing.T) {
+	store := newFakeStorage(t, WithEnableVirtualLink())
+	writeObjects(t, store, map[string]string{"a": "hello"}, WithUserMetadata(map[string]string{"foo": "bar"}))
+	if _, err := store.CreateLink("link", "a"); err != nil {
+		t.Fatalf("create link: %v", err)
+	}
+
+	cases := []struct {
+		name     string
+		path     string
+		expected map[string]string
+	}{
+		{"object", "a", map[string]string{"foo": "bar"}},
+		// The link target is stored as metadata, which should be filtered out.
+		{"link", "link", map[string]string{}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				buf    bytes.Buffer
+				actual map[string]string
+			)
+			_, err := store.Read(tt.path, &buf, WithUserMetadataCallback(func(m map[string]string) {
+				actual = m
+			}))
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			if actual == nil || fmt.Sprint(actual) != fmt.Sprint(tt.expected) {
+				t.Errorf("user metadata: expected %v, actual %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestStatImplicitDir(t *testing.T) {
+	store := newFakeStorage(t, WithEnableVirtualDir())
+	writeObjects(t, store, map[string]string{"implicit/a": "", "marker/": ""})
+
+	for _, path := range []string{"implicit", "marker"} {
+		o, err := store.Stat(path, ps.WithObjectMode(types.ModeDir))
+		if err != nil {
+			t.Fatalf("stat %s: %v", path, err)
+		}
+		if !o.Mode.IsDir() || o.ID != path+"/" {
+			t.Errorf("stat %s: expected dir %s/, actual %s %s", path, path, o.Mode, o.ID)
+		}
+	}
+
+	_, err := store.Stat("none", ps.WithObjectMode(types.ModeDir))
+	if !errors.Is(err, services.ErrObjectNotExist) {
+		t.Errorf("stat none: expected %v, actual %v", services.ErrObjectNotExist, err)
+	}
+	_, err = store.Stat("implicit")
+	if !errors.Is(err, services.ErrObjectNotExist) {
+		t.Errorf("stat implicit as file: expected %v, actual %v", services.ErrObjectNotExist, err)
+	}
+
+	o, err := store.CreateDir("new", WithImplicitDir())
+	if err != nil {
+		t.Fatalf("create dir: %v", err)
+	}
+	if !o.Mode.IsDir() || o.ID != "new/" {
+		t.Errorf("create dir: expected dir new/, actual %s %s", o.Mode, o.ID)
+	}
+	// The marker must not be written for implicit dirs.
+	_, err = store.Stat("new", ps.WithObjectMode(types.ModeDir))
+	if !errors.Is(err, services.ErrObjectNotExist) {
+		t.Errorf("stat new: expected %v, actual %v", services.ErrObjectNotExist, err)
+	}
+}
+
+func TestFollowLinks(t *testing.T) {
+	store := newFakeStorage(t, WithEnableVirtualLink())
+	writeObjects(t, store, map[string]string{"c": "Hello, World!"})
+	for path, target := range map[string]string{
+		"a":       "b",
+		"b":       "c",
+		"dir/rel": "../a",
+		"dir/dot": "./rel",
+		"x":       "y",
+		"y":       "/x",
+	} {
+		if _, err := store.CreateLink(path, target); err != nil {
+			t.Fatalf("create link %s: %v", path, err)
+		}
+	}
+
+	cases := []struct {
+		name     string
+		path     string
+		pairs    []types.Pair
+		expected string
+		err      error
+	}{
+		{"not follow", "a", nil, "", nil},
+		{"link chain", "a", []types.Pair{WithFollowLinks()}, "Hello, World!", nil},
+		{"relative target", "dir/rel", []types.Pair{WithFollowLinks()}, "Hello, World!", nil},
+		{"dot target", "dir/dot", []types.Pair{WithFollowLinks()}, "Hello, World!", nil},
+		{"cycle", "x", []types.Pair{WithFollowLinks()}, "", ErrLinkCycle},
+		{"depth exceeded", "a", []types.Pair{WithFollowLinks(), WithFollowLinksMaxDepth(1)}, "", ErrLinkDepthExceeded},
+		{"not exist", "z", []types.Pair{WithFollowLinks()}, "", services.ErrObjectNotExist},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			_, err := store.Read(tt.path, &buf, tt.pairs...)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("read: expected %v, actual %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("read: expected %q, actual %q", tt.expected, buf.String())
+			}
+		})
+	}
+
+	t.Run("stat", func(t *testing.T) {
+		o, err := store.Stat("dir/rel", WithFollowLinks())
+		if err != nil {
+			t.Fatalf("stat: %v", err)
+		}
+		if o.ID != "c" || !o.Mode.IsRead() || o.Mode.IsLink() {
+			t.Errorf("stat: unexpected object %s with mode %s", o.ID, o.Mode)
+		}
+
+		o, err = store.Stat("dir/rel")
+		if err != nil {
+			t.Fatalf("stat: %v", err)
+		}
+		if !o.Mode.IsLink() || o.MustGetLinkTarget() != "/a" {
+			t.Errorf("stat: unexpected link %s with mode %s", o.MustGetLinkTarget(), o.Mode)
+		}
+	})
+
+	t.Run("query sign http read", func(t *testing.T) {
+		req, err := store.QuerySignHTTPRead("a", time.Minute, WithFollowLinks())
+		if err != nil {
+			t.Fatalf("query sign http read: %v", err)
+		}
+		if req.URL.Path != "/bucket/c" {
+			t.Errorf("path: expected /bucket/c, actual %s", req.URL.Path)
+		}
+	})
+}
+
+func TestHandler(t *testing.T) {
+	store := newFakeStorage(t)
+	writeObjects(t, store, map[string]string{"www/index.html": "<h1>hello</h1>"}, ps.WithContentType("text/html"))
+	writeObjects(t, store, map[string]string{"www/sub/a.txt": "0123456789", "secret.txt": "secret"})
+	o, err := store.Stat("www/index.html")
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	modTime := o.MustGetLastModified()
+
+	h, err := store.Handler("www")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serve := func(method, target string, header http.Header) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest(method, target, nil)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve(http.MethodGet, "/index.html", nil)
+	if w.Code != http.StatusOK || w.Body.String() != "<h1>hello</h1>" {
+		t.Errorf("get: unexpected %d %q", w.Code, w.Body.String())
+	}
+	etag := w.Header().Get("ETag")
+	if etag != o.MustGetEtag() || w.Header().Get("Content-Type") != "text/html" ||
+		w.Header().Get("Last-Modified") != modTime.UTC().Format(http.TimeFormat) {
+		t.Errorf("get: unexpected header %v", w.Header())
+	}
+
+	w = serve(http.MethodGet, "/sub/a.txt", http.Header{"Range": {"bytes=2-4"}})
+	if w.Code != http.StatusPartialContent || w.Body.String() != "234" || w.Header().Get("Content-Range") != "bytes 2-4/10" {
+		t.Errorf("range: unexpected %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+
+	w = serve(http.MethodGet, "/index.html", http.Header{"If-None-Match": {etag}})
+	if w.Code != http.StatusNotModified || w.Header().Get("ETag") != etag {
+		t.Errorf("if none match: unexpected %d %v", w.Code, w.Header())
+	}
+	w = serve(http.MethodGet, "/index.html", http.Header{"If-Modified-Since": {modTime.UTC().Format(http.TimeFormat)}})
+	if w.Code != http.StatusNotModified {
+		t.Errorf("if modified since: expected %d, actual %d", http.StatusNotModified, w.Code)
+	}
+	w = serve(http.MethodHead, "/index.html", nil)
+	if w.Code != http.StatusOK || w.Body.Len() != 0 || w.Header().Get("Content-Length") != "14" {
+		t.Errorf("head: unexpected %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+
+	w = serve(http.MethodGet, "/", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `<a href="./index.html">index.html</a>`) ||
+		!strings.Contains(w.Body.String(), `<a href="./sub/">sub/</a>`) {
+		t.Errorf("list: unexpected %d %q", w.Code, w.Body.String())
+	}
+	w = serve(http.MethodGet, "/sub", nil)
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/sub/" {
+		t.Errorf("dir redirect: unexpected %d %v", w.Code, w.Header())
+	}
+
+	for _, target := range []string{"/missing", "/missing/", "/../secret.txt"} {
+		if w = serve(http.MethodGet, target, nil); w.Code != http.StatusNotFound {
+			t.Errorf("get %s: expected %d, actual %d", target, http.StatusNotFound, w.Code)
+		}
+	}
+	if w = serve(http.MethodPut, "/index.html", nil); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("put: expected %d, actual %d", http.StatusMethodNotAllowed, w.Code)
+	}
+
+	h, err = store.Handler("www", WithHTTPRedirect(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = serve(http.MethodGet, "/index.html", nil)
+	if w.Code != http.StatusTemporaryRedirect || !strings.Contains(w.Header().Get("Location"), "/bucket/www/index.html?") ||
+		!strings.Contains(w.Header().Get("Location"), "X-Amz-Signature=") {
+		t.Errorf("redirect: unexpected %d %v", w.Code, w.Header())
+	}
+}
+
+func TestServiceList(t *testing.T) {
+	srv := s3test.NewServer("a", "b")
+	defer srv.Close()
+
+	servicer, err := NewServicer(
+		ps.WithCredential("hmac:"+testAccessKey+":"+testSecretKey),
+		ps.WithEndpoint(srv.Endpoint()),
+		ps.WithLocation(testLocation),
+		WithForcePathStyle(),
+	)
+	if err != nil {
+		t.Fatalf("new servicer: %v", err)
+	}
+
+	it, err := servicer.List()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	var names []string
+	for {
+		store, err := it.Next()
+		if errors.Is(err, types.IterateDone) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		names = append(names, store.Metadata().Name)
+
+		// Storages returned by list should be usable.
+		if _, err = store.Write("key", bytes.NewReader(nil), 0); err != nil {
+			t.Errorf("write to %s: %v", store.Metadata().Name, err)
+		}
+	}
+	if fmt.Sprint(names) != "[a b]" {
+		t.Errorf("expected [a b], actual %v", names)
+	}
+}
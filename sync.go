@@ -0,0 +1,669 @@
+package s3
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/beyondstorage/go-storage/v4/services"
+	. "github.com/beyondstorage/go-storage/v4/types"
+)
+
+// All available sync compare methods are listed here.
+const (
+	// SyncCompareSizeMtime treats a file as changed if the sizes differ or the source is newer.
+	SyncCompareSizeMtime = "size_mtime"
+	// SyncCompareMD5 treats a file as changed if the sizes or MD5 differ. The ETag of an object uploaded
+	// with multipart is not its MD5, such objects will be compared by size and mtime instead.
+	SyncCompareMD5 = "md5"
+)
+
+// All available sync actions are listed here.
+const (
+	SyncActionUpload   = "upload"
+	SyncActionDownload = "download"
+	SyncActionDelete   = "delete"
+	SyncActionSkip     = "skip"
+)
+
+const (
+	// defaultSyncConcurrency is the default number of files processed concurrently by sync.
+	defaultSyncConcurrency = 4
+	// syncMultipartThreshold is the size above which files will be uploaded with multipart.
+	syncMultipartThreshold = 64 * 1024 * 1024
+	// syncPartSize is the part size of multipart uploads in sync.
+	syncPartSize = 16 * 1024 * 1024
+)
+
+// SyncEvent is the event of a file processed by sync.
+type SyncEvent struct {
+	// Path is the path of the file relative to both the local dir and the storage dir.
+	Path string
+	// Action is one of SyncActionXxx.
+	Action string
+	// Size is the size of the file in the source, or in the destination for SyncActionDelete.
+	Size int64
+	// Err is the error of the action, which is always nil in dry run.
+	Err error
+}
+
+// SyncSummary is the summary of SyncUpload and SyncDownload.
+type SyncSummary struct {
+	// Uploaded is the count of files uploaded.
+	Uploaded int64
+	// Downloaded is the count of files downloaded.
+	Downloaded int64
+	// Deleted is the count of files deleted from the destination.
+	Deleted int64
+	// Skipped is the count of files unchanged.
+	Skipped int64
+	// Bytes is the total size of files transferred.
+	Bytes int64
+	// Errors contains the errors of files failed to sync.
+	Errors []error
+}
+
+// pairStorageSync is the parsed struct for sync operations.
+type pairStorageSync struct {
+	pairs []Pair
+	// Optional pairs
+	HasConcurrency         bool
+	Concurrency            int
+	HasDryRun              bool
+	DryRun                 bool
+	HasExceptedBucketOwner bool
+	ExceptedBucketOwner    string
+	HasStorageClass        bool
+	StorageClass           string
+	HasSyncCallback        bool
+	SyncCallback           func(SyncEvent)
+	HasSyncCompare         bool
+	SyncCompare            string
+	HasSyncDelete          bool
+	SyncDelete             bool
+	HasUserMetadata        bool
+	UserMetadata           map[string]string
+}
+
+func (s *Storage) parsePairStorageSync(opts []Pair) (pairStorageSync, error) {
+	result := pairStorageSync{pairs: opts}
+
+	for _, v := range opts {
+		switch v.Key {
+		case "concurrency":
+			if result.HasConcurrency {
+				continue
+			}
+			result.HasConcurrency = true
+			result.Concurrency = v.Value.(int)
+		case "dry_run":
+			if result.HasDryRun {
+				continue
+			}
+			result.HasDryRun = true
+			result.DryRun = v.Value.(bool)
+		case "excepted_bucket_owner":
+			if result.HasExceptedBucketOwner {
+				continue
+			}
+			result.HasExceptedBucketOwner = true
+			result.ExceptedBucketOwner = v.Value.(string)
+		case "storage_class":
+			if result.HasStorageClass {
+				continue
+			}
+			result.HasStorageClass = true
+			result.StorageClass = v.Value.(string)
+		case "sync_callback":
+			if result.HasSyncCallback {
+				continue
+			}
+			result.HasSyncCallback = true
+			result.SyncCallback = v.Value.(func(SyncEvent))
+		case "sync_compare":
+			if result.HasSyncCompare {
+				continue
+			}
+			result.HasSyncCompare = true
+			result.SyncCompare = v.Value.(string)
+		case "sync_delete":
+			if result.HasSyncDelete {
+				continue
+			}
+			result.HasSyncDelete = true
+			result.SyncDelete = v.Value.(bool)
+		case "user_metadata":
+			if result.HasUserMetadata {
+				continue
+			}
+			result.HasUserMetadata = true
+			result.UserMetadata = v.Value.(map[string]string)
+		default:
+			return pairStorageSync{}, services.PairUnsupportedError{Pair: v}
+		}
+	}
+
+	return result, nil
+}
+
+// SyncUpload will upload changed files in the local dir to the dir path.
+//
+// Files are compared by size and mtime by default, use WithSyncCompare to compare by MD5 instead.
+// Use WithSyncDelete to delete objects which don't exist in the local dir, and WithDryRun to only
+// report the actions. Files will be processed concurrently, use WithConcurrency to control the
+// concurrency, 4 by default. Files larger than 64MB will be uploaded with multipart.
+// Use WithStorageClass and WithUserMetadata to set the storage class and user metadata of uploaded objects.
+//
+// Failures of single files will be collected in the summary and passed to the callback specified by
+// WithSyncCallback, err will only be returned if the files can't be listed.
+func (s *Storage) SyncUpload(dir string, path string, pairs ...Pair) (summary *SyncSummary, err error) {
+	ctx := context.Background()
+	return s.SyncUploadWithContext(ctx, dir, path, pairs...)
+}
+
+// SyncUploadWithContext will upload changed files in the local dir to the dir path.
+//
+// Files are compared by size and mtime by default, use WithSyncCompare to compare by MD5 instead.
+// Use WithSyncDelete to delete objects which don't exist in the local dir, and WithDryRun to only
+// report the actions. Files will be processed concurrently, use WithConcurrency to control the
+// concurrency, 4 by default. Files larger than 64MB will be uploaded with multipart.
+// Use WithStorageClass and WithUserMetadata to set the storage class and user metadata of uploaded objects.
+//
+// Failures of single files will be collected in the summary and passed to the callback specified by
+// WithSyncCallback, err will only be returned if the files can't be listed.
+func (s *Storage) SyncUploadWithContext(ctx context.Context, dir string, path string, pairs ...Pair) (summary *SyncSummary, err error) {
+	defer func() {
+		err = s.formatError("sync_upload", err, path)
+	}()
+
+	opt, err := s.parsePairStorageSync(pairs)
+	if err != nil {
+		return
+	}
+	return s.syncUpload(ctx, dir, strings.ReplaceAll(path, "\\", "/"), opt)
+}
+
+// SyncDownload will download changed objects under the dir path to the local dir.
+//
+// Objects are compared by size and mtime by default, use WithSyncCompare to compare by MD5 instead.
+// The mtime of downloaded files will be set to the last modified time of the objects.
+// Use WithSyncDelete to delete local files which don't exist in storage, and WithDryRun to only
+// report the actions. Objects will be processed concurrently, use WithConcurrency to control the
+// concurrency, 4 by default.
+//
+// Failures of single files will be collected in the summary and passed to the callback specified by
+// WithSyncCallback, err will only be returned if the files can't be listed.
+func (s *Storage) SyncDownload(path string, dir string, pairs ...Pair) (summary *SyncSummary, err error) {
+	ctx := context.Background()
+	return s.SyncDownloadWithContext(ctx, path, dir, pairs...)
+}
+
+// SyncDownloadWithContext will download changed objects under the dir path to the local dir.
+//
+// Objects are compared by size and mtime by default, use WithSyncCompare to compare by MD5 instead.
+// The mtime of downloaded files will be set to the last modified time of the objects.
+// Use WithSyncDelete to delete local files which don't exist in storage, and WithDryRun to only
+// report the actions. Objects will be processed concurrently, use WithConcurrency to control the
+// concurrency, 4 by default.
+//
+// Failures of single files will be collected in the summary and passed to the callback specified by
+// WithSyncCallback, err will only be returned if the files can't be listed.
+func (s *Storage) SyncDownloadWithContext(ctx context.Context, path string, dir string, pairs ...Pair) (summary *SyncSummary, err error) {
+	defer func() {
+		err = s.formatError("sync_download", err, path)
+	}()
+
+	opt, err := s.parsePairStorageSync(pairs)
+	if err != nil {
+		return
+	}
+	return s.syncDownload(ctx, strings.ReplaceAll(path, "\\", "/"), dir, opt)
+}
+
+func (s *Storage) syncUpload(ctx context.Context, dir string, path string, opt pairStorageSync) (summary *SyncSummary, err error) {
+	y, err := s.newSyncer(dir, path, true, opt)
+	if err != nil {
+		return
+	}
+
+	y.src, err = listLocalSyncFiles(dir)
+	if err != nil {
+		return
+	}
+	y.dst, err = s.listSyncFiles(ctx, y.prefix, opt.ExceptedBucketOwner)
+	if err != nil {
+		return
+	}
+	return y.run(ctx), nil
+}
+
+func (s *Storage) syncDownload(ctx context.Context, path string, dir string, opt pairStorageSync) (summary *SyncSummary, err error) {
+	y, err := s.newSyncer(dir, path, false, opt)
+	if err != nil {
+		return
+	}
+
+	y.src, err = s.listSyncFiles(ctx, y.prefix, opt.ExceptedBucketOwner)
+	if err != nil {
+		return
+	}
+	y.dst, err = listLocalSyncFiles(dir)
+	// The local dir will be created while downloading.
+	if os.IsNotExist(err) {
+		y.dst, err = make(map[string]syncFile), nil
+	}
+	if err != nil {
+		return
+	}
+	return y.run(ctx), nil
+}
+
+// syncFile is the state of a file on either side of sync.
+type syncFile struct {
+	size    int64
+	modTime time.Time
+	// etag and storageClass are only available for objects.
+	etag         string
+	storageClass string
+}
+
+// listLocalSyncFiles will return all regular files under dir, keyed by their slash-separated relative paths.
+func listLocalSyncFiles(dir string) (files map[string]syncFile, err error) {
+	files = make(map[string]syncFile)
+	err = filepath.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = syncFile{size: fi.Size(), modTime: fi.ModTime()}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// listSyncFiles will return all objects under the dir prefix except dir markers, keyed by their paths relative to prefix.
+func (s *Storage) listSyncFiles(ctx context.Context, prefix string, expectedBucketOwner string) (files map[string]syncFile, err error) {
+	files = make(map[string]syncFile)
+
+	input := &objectPageStatus{
+		maxKeys:             listMaxKeysMaximum,
+		prefix:              s.getAbsPath(prefix),
+		expectedBucketOwner: expectedBucketOwner,
+	}
+	for {
+		// Use the list result directly, as getters of objects returned by list will trigger stat.
+		contents, _, truncated, err := s.listObjects(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range contents {
+			key := aws.ToString(v.Key)
+			if strings.HasSuffix(key, "/") {
+				continue
+			}
+			files[strings.TrimPrefix(s.getRelPath(key), prefix)] = syncFile{
+				size:         v.Size,
+				modTime:      aws.ToTime(v.LastModified),
+				etag:         strings.Trim(aws.ToString(v.ETag), `"`),
+				storageClass: string(v.StorageClass),
+			}
+		}
+		if !truncated {
+			return files, nil
+		}
+	}
+}
+
+// syncer holds the state of a sync between a local dir and a storage dir.
+type syncer struct {
+	s      *Storage
+	dir    string
+	prefix string
+	// upload means syncing from the local dir to storage.
+	upload  bool
+	compare string
+	opt     pairStorageSync
+
+	src map[string]syncFile
+	dst map[string]syncFile
+}
+
+func (s *Storage) newSyncer(dir string, path string, upload bool, opt pairStorageSync) (y *syncer, err error) {
+	y = &syncer{
+		s:       s,
+		dir:     dir,
+		prefix:  path,
+		upload:  upload,
+		compare: SyncCompareSizeMtime,
+		opt:     opt,
+	}
+	// Sync the path as a dir, so that "dir" will not match "dir2/".
+	if y.prefix != "" && !strings.HasSuffix(y.prefix, "/") {
+		y.prefix += "/"
+	}
+	if opt.HasSyncCompare {
+		if opt.SyncCompare != SyncCompareSizeMtime && opt.SyncCompare != SyncCompareMD5 {
+			return nil, fmt.Errorf("sync compare %s not supported: %w", opt.SyncCompare, services.ErrCapabilityInsufficient)
+		}
+		y.compare = opt.SyncCompare
+	}
+	return y, nil
+}
+
+func (y *syncer) run(ctx context.Context) (summary *SyncSummary) {
+	concurrency := defaultSyncConcurrency
+	if y.opt.HasConcurrency && y.opt.Concurrency > 0 {
+		concurrency = y.opt.Concurrency
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+		ch = make(chan SyncEvent)
+	)
+	summary = &SyncSummary{}
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for e := range ch {
+				e = y.process(ctx, e)
+
+				// Hold the lock while calling the callback, so that events are reported one by one.
+				mu.Lock()
+				summary.add(e)
+				if y.opt.HasSyncCallback {
+					y.opt.SyncCallback(e)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for p, f := range y.src {
+		ch <- SyncEvent{Path: p, Size: f.size}
+	}
+	if y.opt.HasSyncDelete && y.opt.SyncDelete {
+		for p, f := range y.dst {
+			if _, ok := y.src[p]; !ok {
+				ch <- SyncEvent{Path: p, Action: SyncActionDelete, Size: f.size}
+			}
+		}
+	}
+	close(ch)
+	wg.Wait()
+
+	return summary
+}
+
+func (summary *SyncSummary) add(e SyncEvent) {
+	if e.Err != nil {
+		summary.Errors = append(summary.Errors, e.Err)
+		return
+	}
+
+	switch e.Action {
+	case SyncActionUpload:
+		summary.Uploaded++
+		summary.Bytes += e.Size
+	case SyncActionDownload:
+		summary.Downloaded++
+		summary.Bytes += e.Size
+	case SyncActionDelete:
+		summary.Deleted++
+	case SyncActionSkip:
+		summary.Skipped++
+	}
+}
+
+// process will decide the action of the file if not decided, and execute it unless in dry run.
+func (y *syncer) process(ctx context.Context, e SyncEvent) SyncEvent {
+	op := "sync_download"
+	if y.upload {
+		op = "sync_upload"
+	}
+
+	var err error
+	if e.Action == "" {
+		e.Action, err = y.action(e.Path)
+		if err != nil {
+			e.Err = y.s.formatError(op, err, y.prefix+e.Path)
+			return e
+		}
+	}
+	if e.Action == SyncActionSkip || (y.opt.HasDryRun && y.opt.DryRun) {
+		return e
+	}
+
+	switch e.Action {
+	case SyncActionUpload:
+		err = y.uploadFile(ctx, e.Path, e.Size)
+	case SyncActionDownload:
+		err = y.downloadFile(ctx, e.Path)
+	case SyncActionDelete:
+		err = y.deleteFile(ctx, e.Path)
+	}
+	if err != nil {
+		e.Err = y.s.formatError(op, err, y.prefix+e.Path)
+	}
+	return e
+}
+
+// action will compare the file in the source with the destination.
+func (y *syncer) action(p string) (action string, err error) {
+	changed, err := y.changed(p)
+	if err != nil {
+		return "", err
+	}
+	if !changed {
+		return SyncActionSkip, nil
+	}
+	if y.upload {
+		return SyncActionUpload, nil
+	}
+	return SyncActionDownload, nil
+}
+
+func (y *syncer) changed(p string) (bool, error) {
+	src := y.src[p]
+	dst, ok := y.dst[p]
+	if !ok || src.size != dst.size {
+		return true, nil
+	}
+
+	object := dst
+	if !y.upload {
+		object = src
+	}
+	if y.compare == SyncCompareMD5 && object.etag != "" && !strings.Contains(object.etag, "-") {
+		lp, err := y.localPath(p)
+		if err != nil {
+			return false, err
+		}
+		sum, err := fileMD5(lp)
+		if err != nil {
+			return false, err
+		}
+		return sum != object.etag, nil
+	}
+	// The last modified time of objects is the upload time, so only the newer source is treated as changed.
+	return src.modTime.After(dst.modTime), nil
+}
+
+// localPath will return the path of the file in the local dir.
+func (y *syncer) localPath(p string) (string, error) {
+	// Keys could contain ".." or empty segments, make sure the file is inside the local dir.
+	if path.Clean("/"+p) != "/"+p {
+		return "", fmt.Errorf("path %s is not a clean relative path: %w", p, services.ErrRestrictionDissatisfied)
+	}
+	return filepath.Join(y.dir, filepath.FromSlash(p)), nil
+}
+
+func (y *syncer) uploadFile(ctx context.Context, p string, size int64) (err error) {
+	lp, err := y.localPath(p)
+	if err != nil {
+		return
+	}
+	f, err := os.Open(lp)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	opt := pairStorageWrite{
+		HasExceptedBucketOwner: y.opt.HasExceptedBucketOwner,
+		ExceptedBucketOwner:    y.opt.ExceptedBucketOwner,
+		HasStorageClass:        y.opt.HasStorageClass,
+		StorageClass:           y.opt.StorageClass,
+		HasUserMetadata:        y.opt.HasUserMetadata,
+		UserMetadata:           y.opt.UserMetadata,
+	}
+	if size <= syncMultipartThreshold {
+		_, err = y.s.write(ctx, y.prefix+p, f, size, opt)
+		return err
+	}
+	return y.s.writeWithMultipart(ctx, y.prefix+p, f, size, opt)
+}
+
+// writeWithMultipart will upload the content of r to path with multipart, parts are uploaded one by one.
+//
+// Only storage_class, user_metadata and excepted_bucket_owner of opt are supported.
+func (s *Storage) writeWithMultipart(ctx context.Context, path string, r io.ReaderAt, size int64, opt pairStorageWrite) (err error) {
+	o, err := s.createMultipart(ctx, path, pairStorageCreateMultipart{
+		HasExceptedBucketOwner: opt.HasExceptedBucketOwner,
+		ExceptedBucketOwner:    opt.ExceptedBucketOwner,
+		HasStorageClass:        opt.HasStorageClass,
+		StorageClass:           opt.StorageClass,
+		HasUserMetadata:        opt.HasUserMetadata,
+		UserMetadata:           opt.UserMetadata,
+	})
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if err == nil {
+			return
+		}
+		// Delete with multipart id will also delete the object, so we abort the upload directly.
+		_, _ = s.service.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(s.name),
+			Key:      aws.String(o.ID),
+			UploadId: aws.String(o.MustGetMultipartID()),
+		})
+	}()
+
+	partSize := calculatePartSize(size, syncPartSize)
+	var parts []*Part
+	for offset, index := int64(0), 0; offset < size; offset, index = offset+partSize, index+1 {
+		n := partSize
+		if offset+n > size {
+			n = size - offset
+		}
+
+		_, part, err := s.writeMultipart(ctx, o, io.NewSectionReader(r, offset, n), n, index, pairStorageWriteMultipart{
+			HasExceptedBucketOwner: opt.HasExceptedBucketOwner,
+			ExceptedBucketOwner:    opt.ExceptedBucketOwner,
+		})
+		if err != nil {
+			return err
+		}
+		parts = append(parts, part)
+	}
+	return s.completeMultipart(ctx, o, parts, pairStorageCompleteMultipart{
+		HasExceptedBucketOwner: opt.HasExceptedBucketOwner,
+		ExceptedBucketOwner:    opt.ExceptedBucketOwner,
+	})
+}
+
+func (y *syncer) downloadFile(ctx context.Context, p string) (err error) {
+	lp, err := y.localPath(p)
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(lp), 0755); err != nil {
+		return
+	}
+
+	// Download to a temp file first, so that the file will not be broken if the download fails.
+	// Keep using ioutil.TempFile instead of os.CreateTemp, which requires go1.16 while go.mod declares go 1.14.
+	f, err := ioutil.TempFile(filepath.Dir(lp), "."+filepath.Base(lp)+".*.tmp")
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	_, err = y.s.read(ctx, y.prefix+p, f, pairStorageRead{
+		HasExceptedBucketOwner: y.opt.HasExceptedBucketOwner,
+		ExceptedBucketOwner:    y.opt.ExceptedBucketOwner,
+	})
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return
+	}
+	if err = os.Rename(f.Name(), lp); err != nil {
+		return
+	}
+	// Keep the mtime same as the object, so that the file will be skipped in the next sync.
+	modTime := y.src[p].modTime
+	return os.Chtimes(lp, modTime, modTime)
+}
+
+func (y *syncer) deleteFile(ctx context.Context, p string) (err error) {
+	if y.upload {
+		return y.s.delete(ctx, y.prefix+p, pairStorageDelete{
+			HasExceptedBucketOwner: y.opt.HasExceptedBucketOwner,
+			ExceptedBucketOwner:    y.opt.ExceptedBucketOwner,
+		})
+	}
+
+	lp, err := y.localPath(p)
+	if err != nil {
+		return
+	}
+	return os.Remove(lp)
+}
+
+// fileMD5 will return the hex encoded MD5 of the file, which is the same as the ETag of single part objects.
+func fileMD5(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := md5.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
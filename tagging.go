@@ -0,0 +1,150 @@
+package s3
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/beyondstorage/go-storage/v4/services"
+	. "github.com/beyondstorage/go-storage/v4/types"
+)
+
+// pairStorageObjectTagging is the parsed struct for object tagging operations.
+type pairStorageObjectTagging struct {
+	pairs []Pair
+	// Optional pairs
+	HasExceptedBucketOwner bool
+	ExceptedBucketOwner    string
+}
+
+func (s *Storage) parsePairStorageObjectTagging(opts []Pair) (pairStorageObjectTagging, error) {
+	result := pairStorageObjectTagging{pairs: opts}
+
+	for _, v := range opts {
+		switch v.Key {
+		case "excepted_bucket_owner":
+			if result.HasExceptedBucketOwner {
+				continue
+			}
+			result.HasExceptedBucketOwner = true
+			result.ExceptedBucketOwner = v.Value.(string)
+		default:
+			return pairStorageObjectTagging{}, services.PairUnsupportedError{Pair: v}
+		}
+	}
+
+	return result, nil
+}
+
+// GetObjectTagging will return the tag-set of the object.
+func (s *Storage) GetObjectTagging(path string, pairs ...Pair) (tags map[string]string, err error) {
+	ctx := context.Background()
+	return s.GetObjectTaggingWithContext(ctx, path, pairs...)
+}
+
+// GetObjectTaggingWithContext will return the tag-set of the object.
+func (s *Storage) GetObjectTaggingWithContext(ctx context.Context, path string, pairs ...Pair) (tags map[string]string, err error) {
+	defer func() {
+		err = s.formatError("get_object_tagging", err, path)
+	}()
+
+	opt, err := s.parsePairStorageObjectTagging(pairs)
+	if err != nil {
+		return
+	}
+	return s.getObjectTagging(ctx, strings.ReplaceAll(path, "\\", "/"), opt)
+}
+
+// PutObjectTagging will replace the whole tag-set of the object with tags.
+func (s *Storage) PutObjectTagging(path string, tags map[string]string, pairs ...Pair) (err error) {
+	ctx := context.Background()
+	return s.PutObjectTaggingWithContext(ctx, path, tags, pairs...)
+}
+
+// PutObjectTaggingWithContext will replace the whole tag-set of the object with tags.
+func (s *Storage) PutObjectTaggingWithContext(ctx context.Context, path string, tags map[string]string, pairs ...Pair) (err error) {
+	defer func() {
+		err = s.formatError("put_object_tagging", err, path)
+	}()
+
+	opt, err := s.parsePairStorageObjectTagging(pairs)
+	if err != nil {
+		return
+	}
+	return s.putObjectTagging(ctx, strings.ReplaceAll(path, "\\", "/"), tags, opt)
+}
+
+// DeleteObjectTagging will remove the whole tag-set of the object.
+func (s *Storage) DeleteObjectTagging(path string, pairs ...Pair) (err error) {
+	ctx := context.Background()
+	return s.DeleteObjectTaggingWithContext(ctx, path, pairs...)
+}
+
+// DeleteObjectTaggingWithContext will remove the whole tag-set of the object.
+func (s *Storage) DeleteObjectTaggingWithContext(ctx context.Context, path string, pairs ...Pair) (err error) {
+	defer func() {
+		err = s.formatError("delete_object_tagging", err, path)
+	}()
+
+	opt, err := s.parsePairStorageObjectTagging(pairs)
+	if err != nil {
+		return
+	}
+	return s.deleteObjectTagging(ctx, strings.ReplaceAll(path, "\\", "/"), opt)
+}
+
+func (s *Storage) getObjectTagging(ctx context.Context, path string, opt pairStorageObjectTagging) (tags map[string]string, err error) {
+	input := &s3.GetObjectTaggingInput{
+		Bucket: aws.String(s.name),
+		Key:    aws.String(s.getAbsPath(path)),
+	}
+	if opt.HasExceptedBucketOwner {
+		input.ExpectedBucketOwner = &opt.ExceptedBucketOwner
+	}
+	output, err := s.service.GetObjectTagging(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	tags = make(map[string]string, len(output.TagSet))
+	for _, v := range output.TagSet {
+		tags[aws.ToString(v.Key)] = aws.ToString(v.Value)
+	}
+	return tags, nil
+}
+
+func (s *Storage) putObjectTagging(ctx context.Context, path string, tags map[string]string, opt pairStorageObjectTagging) (err error) {
+	tagging := &s3types.Tagging{}
+	for k, v := range tags {
+		tagging.TagSet = append(tagging.TagSet, s3types.Tag{
+			Key:   aws.String(k),
+			Value: aws.String(v),
+		})
+	}
+
+	input := &s3.PutObjectTaggingInput{
+		Bucket:  aws.String(s.name),
+		Key:     aws.String(s.getAbsPath(path)),
+		Tagging: tagging,
+	}
+	if opt.HasExceptedBucketOwner {
+		input.ExpectedBucketOwner = &opt.ExceptedBucketOwner
+	}
+	_, err = s.service.PutObjectTagging(ctx, input)
+	return err
+}
+
+func (s *Storage) deleteObjectTagging(ctx context.Context, path string, opt pairStorageObjectTagging) (err error) {
+	input := &s3.DeleteObjectTaggingInput{
+		Bucket: aws.String(s.name),
+		Key:    aws.String(s.getAbsPath(path)),
+	}
+	if opt.HasExceptedBucketOwner {
+		input.ExpectedBucketOwner = &opt.ExceptedBucketOwner
+	}
+	_, err = s.service.DeleteObjectTagging(ctx, input)
+	return err
+}
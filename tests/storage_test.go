@@ -5,41 +5,29 @@ import (
 	"os"
 	"testing"
 
+	"github.com/google/uuid"
+
 	tests "github.com/beyondstorage/go-integration-test/v4"
+	s3 "github.com/beyondstorage/go-service-s3/v2"
 )
 
 func TestStorage(t *testing.T) {
-	if os.Getenv("STORAGE_S3_INTEGRATION_TEST") != "on" {
-		t.Skipf("STORAGE_S3_INTEGRATION_TEST is not 'on', skipped")
-	}
 	tests.TestStorager(t, setupTest(t))
 }
 
 func TestMultiparter(t *testing.T) {
-	if os.Getenv("STORAGE_S3_INTEGRATION_TEST") != "on" {
-		t.Skipf("STORAGE_S3_INTEGRATION_TEST is not 'on', skipped")
-	}
 	tests.TestMultiparter(t, setupTest(t))
 }
 
 func TestDirer(t *testing.T) {
-	if os.Getenv("STORAGE_S3_INTEGRATION_TEST") != "on" {
-		t.Skipf("STORAGE_S3_INTEGRATION_TEST is not 'on', skipped")
-	}
 	tests.TestDirer(t, setupTest(t))
 }
 
 func TestLinker(t *testing.T) {
-	if os.Getenv("STORAGE_S3_INTEGRATION_TEST") != "on" {
-		t.Skipf("STORAGE_S3_INTEGRATION_TEST is not 'on', skipped")
-	}
 	tests.TestLinker(t, setupTest(t))
 }
 
 func TestHTTPSigner(t *testing.T) {
-	if os.Getenv("STORAGE_S3_INTEGRATION_TEST") != "on" {
-		t.Skipf("STORAGE_S3_INTEGRATION_TEST is not 'on', skipped")
-	}
 	tests.TestStorageHTTPSignerWrite(t, setupTest(t))
 	tests.TestStorageHTTPSignerRead(t, setupTest(t))
 	// presign operations don't support DeleteObject & CreateMultipartUpload
@@ -51,9 +39,6 @@ func TestHTTPSigner(t *testing.T) {
 
 // https://github.com/beyondstorage/go-storage/issues/741
 func TestIssue741(t *testing.T) {
-	if os.Getenv("STORAGE_S3_INTEGRATION_TEST") != "on" {
-		t.Skipf("STORAGE_S3_INTEGRATION_TEST is not 'on', skipped")
-	}
 	store := setupTest(t)
 
 	content := []byte("Hello, World!")
@@ -66,3 +51,139 @@ func TestIssue741(t *testing.T) {
 	}
 	return
 }
+
+func TestUserMetadata(t *testing.T) {
+	store := setupTest(t)
+
+	path := uuid.New().String()
+	content := []byte("Hello, World!")
+	metadata := map[string]string{"owner": "beyondstorage"}
+
+	_, err := store.Write(path, bytes.NewReader(content), int64(len(content)), s3.WithUserMetadata(metadata))
+	if err != nil {
+		t.Errorf("write: %v", err)
+		return
+	}
+	defer func() {
+		err := store.Delete(path)
+		if err != nil {
+			t.Errorf("delete: %v", err)
+		}
+	}()
+
+	o, err := store.Stat(path)
+	if err != nil {
+		t.Errorf("stat: %v", err)
+		return
+	}
+	um, ok := o.GetUserMetadata()
+	if !ok || um["owner"] != metadata["owner"] {
+		t.Errorf("user metadata: expected %v, actual %v", metadata, um)
+	}
+}
+
+func TestObjectTagging(t *testing.T) {
+	if os.Getenv("STORAGE_S3_INTEGRATION_TEST") != "on" {
+		t.Skipf("STORAGE_S3_INTEGRATION_TEST is not 'on', skipped")
+	}
+	store := setupTest(t).(*s3.Storage)
+
+	path := uuid.New().String()
+	content := []byte("Hello, World!")
+
+	_, err := store.Write(path, bytes.NewReader(content), int64(len(content)), s3.WithTagging(map[string]string{"project": "a"}))
+	if err != nil {
+		t.Errorf("write: %v", err)
+		return
+	}
+	defer func() {
+		err := store.Delete(path)
+		if err != nil {
+			t.Errorf("delete: %v", err)
+		}
+	}()
+
+	o, err := store.Stat(path)
+	if err != nil {
+		t.Errorf("stat: %v", err)
+		return
+	}
+	if n := s3.GetObjectSystemMetadata(o).TagCount; n != 1 {
+		t.Errorf("tag count: expected 1, actual %d", n)
+	}
+
+	err = store.PutObjectTagging(path, map[string]string{"project": "b", "team": "c"})
+	if err != nil {
+		t.Errorf("put object tagging: %v", err)
+		return
+	}
+	tags, err := store.GetObjectTagging(path)
+	if err != nil {
+		t.Errorf("get object tagging: %v", err)
+		return
+	}
+	if len(tags) != 2 || tags["project"] != "b" || tags["team"] != "c" {
+		t.Errorf("get object tagging: unexpected tags %v", tags)
+	}
+
+	err = store.DeleteObjectTagging(path)
+	if err != nil {
+		t.Errorf("delete object tagging: %v", err)
+		return
+	}
+	tags, err = store.GetObjectTagging(path)
+	if err != nil {
+		t.Errorf("get object tagging: %v", err)
+		return
+	}
+	if len(tags) != 0 {
+		t.Errorf("get object tagging: expected no tags, actual %v", tags)
+	}
+}
+
+func TestTransitionStorageClass(t *testing.T) {
+	if os.Getenv("STORAGE_S3_INTEGRATION_TEST") != "on" {
+		t.Skipf("STORAGE_S3_INTEGRATION_TEST is not 'on', skipped")
+	}
+	store := setupTest(t).(*s3.Storage)
+
+	path := uuid.New().String()
+	content := []byte("Hello, World!")
+	metadata := map[string]string{"owner": "beyondstorage"}
+
+	_, err := store.Write(path, bytes.NewReader(content), int64(len(content)),
+		s3.WithUserMetadata(metadata), s3.WithTagging(map[string]string{"project": "a"}))
+	if err != nil {
+		t.Errorf("write: %v", err)
+		return
+	}
+	defer func() {
+		err := store.Delete(path)
+		if err != nil {
+			t.Errorf("delete: %v", err)
+		}
+	}()
+
+	err = store.TransitionStorageClass(path, string(s3.StorageClassStandardIa))
+	if err != nil {
+		t.Errorf("transition storage class: %v", err)
+		return
+	}
+
+	o, err := store.Stat(path)
+	if err != nil {
+		t.Errorf("stat: %v", err)
+		return
+	}
+	sm := s3.GetObjectSystemMetadata(o)
+	if sm.StorageClass != string(s3.StorageClassStandardIa) {
+		t.Errorf("storage class: expected %s, actual %s", s3.StorageClassStandardIa, sm.StorageClass)
+	}
+	if sm.TagCount != 1 {
+		t.Errorf("tag count: expected 1, actual %d", sm.TagCount)
+	}
+	um, ok := o.GetUserMetadata()
+	if !ok || um["owner"] != metadata["owner"] {
+		t.Errorf("user metadata: expected %v, actual %v", metadata, um)
+	}
+}
@@ -7,11 +7,15 @@ import (
 	"github.com/google/uuid"
 
 	s3 "github.com/beyondstorage/go-service-s3/v2"
+	"github.com/beyondstorage/go-service-s3/v2/s3test"
 	ps "github.com/beyondstorage/go-storage/v4/pairs"
 	"github.com/beyondstorage/go-storage/v4/types"
 )
 
 func setupTest(t *testing.T) types.Storager {
+	if os.Getenv("STORAGE_S3_INTEGRATION_TEST") != "on" {
+		return setupFakeTest(t)
+	}
 	t.Log("Setup test for s3")
 
 	store, err := s3.NewStorager(
@@ -29,3 +33,39 @@ func setupTest(t *testing.T) types.Storager {
 	}
 	return store
 }
+
+// setupFakeTest will setup test against an in-memory fake s3 server, which will be closed after the test.
+func setupFakeTest(t *testing.T) types.Storager {
+	t.Log("Setup test for fake s3")
+
+	srv := s3test.NewServer()
+	t.Cleanup(srv.Close)
+
+	pairs := []types.Pair{
+		ps.WithCredential("hmac:access_key:secret_key"),
+		ps.WithEndpoint(srv.Endpoint()),
+		ps.WithLocation("us-east-1"),
+		s3.WithForcePathStyle(),
+	}
+	servicer, err := s3.NewServicer(pairs...)
+	if err != nil {
+		t.Fatalf("new servicer: %v", err)
+	}
+	name := uuid.New().String()
+	if _, err = servicer.Create(name, ps.WithLocation("us-east-1")); err != nil {
+		t.Fatalf("create bucket: %v", err)
+	}
+
+	store, err := s3.NewStorager(append(pairs,
+		ps.WithName(name),
+		ps.WithWorkDir("/"+uuid.New().String()+"/"),
+		s3.WithStorageFeatures(s3.StorageFeatures{
+			VirtualDir:  true,
+			VirtualLink: true,
+		}),
+	)...)
+	if err != nil {
+		t.Fatalf("new storager: %v", err)
+	}
+	return store
+}
@@ -0,0 +1,397 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/beyondstorage/go-storage/v4/services"
+	. "github.com/beyondstorage/go-storage/v4/types"
+)
+
+// defaultTransitionConcurrency is the default concurrency of TransitionStorageClassBulk.
+const defaultTransitionConcurrency = 4
+
+// TransitionSummary is the summary of TransitionStorageClassBulk.
+type TransitionSummary struct {
+	// Objects is the count of objects transitioned.
+	Objects int64
+	// Bytes is the total size of objects transitioned.
+	Bytes int64
+	// Skipped is the count of dirs and objects already in the target storage class.
+	Skipped int64
+	// Errors contains the errors of objects failed to transition.
+	Errors []error
+}
+
+// pairStorageTransition is the parsed struct for storage class transition operations.
+type pairStorageTransition struct {
+	pairs []Pair
+	// Optional pairs
+	HasConcurrency                           bool
+	Concurrency                              int
+	HasExceptedBucketOwner                   bool
+	ExceptedBucketOwner                      string
+	HasServerSideEncryptionCustomerAlgorithm bool
+	ServerSideEncryptionCustomerAlgorithm    string
+	HasServerSideEncryptionCustomerKey       bool
+	ServerSideEncryptionCustomerKey          []byte
+}
+
+func (s *Storage) parsePairStorageTransition(opts []Pair) (pairStorageTransition, error) {
+	result := pairStorageTransition{pairs: opts}
+
+	for _, v := range opts {
+		switch v.Key {
+		case "concurrency":
+			if result.HasConcurrency {
+				continue
+			}
+			result.HasConcurrency = true
+			result.Concurrency = v.Value.(int)
+		case "excepted_bucket_owner":
+			if result.HasExceptedBucketOwner {
+				continue
+			}
+			result.HasExceptedBucketOwner = true
+			result.ExceptedBucketOwner = v.Value.(string)
+		case "server_side_encryption_customer_algorithm":
+			if result.HasServerSideEncryptionCustomerAlgorithm {
+				continue
+			}
+			result.HasServerSideEncryptionCustomerAlgorithm = true
+			result.ServerSideEncryptionCustomerAlgorithm = v.Value.(string)
+		case "server_side_encryption_customer_key":
+			if result.HasServerSideEncryptionCustomerKey {
+				continue
+			}
+			result.HasServerSideEncryptionCustomerKey = true
+			result.ServerSideEncryptionCustomerKey = v.Value.([]byte)
+		default:
+			return pairStorageTransition{}, services.PairUnsupportedError{Pair: v}
+		}
+	}
+
+	return result, nil
+}
+
+// TransitionStorageClass will change the storage class of the object in place by copying it to itself.
+//
+// User metadata, system metadata, tags and server-side encryption settings of the object will be preserved,
+// the ACL will be reset to the bucket's default. Objects larger than 5GB will be copied with multipart copy.
+// An SSE-C encrypted object requires the same customer key to be passed via pairs.
+func (s *Storage) TransitionStorageClass(path string, storageClass string, pairs ...Pair) (err error) {
+	ctx := context.Background()
+	return s.TransitionStorageClassWithContext(ctx, path, storageClass, pairs...)
+}
+
+// TransitionStorageClassWithContext will change the storage class of the object in place by copying it to itself.
+//
+// User metadata, system metadata, tags and server-side encryption settings of the object will be preserved,
+// the ACL will be reset to the bucket's default. Objects larger than 5GB will be copied with multipart copy.
+// An SSE-C encrypted object requires the same customer key to be passed via pairs.
+func (s *Storage) TransitionStorageClassWithContext(ctx context.Context, path string, storageClass string, pairs ...Pair) (err error) {
+	defer func() {
+		err = s.formatError("transition_storage_class", err, path)
+	}()
+
+	opt, err := s.parsePairStorageTransition(pairs)
+	if err != nil {
+		return
+	}
+	_, _, err = s.transitionStorageClass(ctx, strings.ReplaceAll(path, "\\", "/"), storageClass, opt)
+	return err
+}
+
+// TransitionStorageClassBulk will change the storage class of all objects returned by it.
+//
+// Objects will be transitioned concurrently, use WithConcurrency to control the concurrency, 4 by default.
+// Failures of single objects will be collected in the summary instead of stopping the whole transition,
+// err will only be returned if the iterator fails.
+func (s *Storage) TransitionStorageClassBulk(it *ObjectIterator, storageClass string, pairs ...Pair) (summary *TransitionSummary, err error) {
+	ctx := context.Background()
+	return s.TransitionStorageClassBulkWithContext(ctx, it, storageClass, pairs...)
+}
+
+// TransitionStorageClassBulkWithContext will change the storage class of all objects returned by it.
+//
+// Objects will be transitioned concurrently, use WithConcurrency to control the concurrency, 4 by default.
+// Failures of single objects will be collected in the summary instead of stopping the whole transition,
+// err will only be returned if the iterator fails or ctx is canceled, ctx.Err() will be returned as is
+// once ctx is canceled.
+func (s *Storage) TransitionStorageClassBulkWithContext(ctx context.Context, it *ObjectIterator, storageClass string, pairs ...Pair) (summary *TransitionSummary, err error) {
+	defer func() {
+		// Keep ctx.Err() as is like Walk, so that it could be matched by errors.Is.
+		if err != nil && err == ctx.Err() {
+			return
+		}
+		err = s.formatError("transition_storage_class_bulk", err)
+	}()
+
+	opt, err := s.parsePairStorageTransition(pairs)
+	if err != nil {
+		return
+	}
+	return s.transitionStorageClassBulk(ctx, it, storageClass, opt)
+}
+
+func (s *Storage) transitionStorageClassBulk(ctx context.Context, it *ObjectIterator, storageClass string, opt pairStorageTransition) (summary *TransitionSummary, err error) {
+	concurrency := defaultTransitionConcurrency
+	if opt.HasConcurrency && opt.Concurrency > 0 {
+		concurrency = opt.Concurrency
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+		ch = make(chan string)
+	)
+	summary = &TransitionSummary{}
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for path := range ch {
+				size, skipped, err := s.transitionStorageClass(ctx, path, storageClass, opt)
+
+				mu.Lock()
+				if err != nil {
+					summary.Errors = append(summary.Errors, s.formatError("transition_storage_class", err, path))
+				} else if skipped {
+					summary.Skipped++
+				} else {
+					summary.Objects++
+					summary.Bytes += size
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+produce:
+	for {
+		o, ierr := it.Next()
+		if ierr != nil {
+			if !errors.Is(ierr, IterateDone) {
+				err = ierr
+			}
+			break
+		}
+
+		// Don't check the storage class here, getters of objects returned by list will trigger stat.
+		if o.Mode.IsDir() {
+			mu.Lock()
+			summary.Skipped++
+			mu.Unlock()
+			continue
+		}
+		select {
+		case ch <- o.Path:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break produce
+		}
+	}
+	close(ch)
+	wg.Wait()
+
+	return summary, err
+}
+
+func (s *Storage) transitionStorageClass(ctx context.Context, path string, storageClass string, opt pairStorageTransition) (size int64, skipped bool, err error) {
+	rp := s.getAbsPath(path)
+
+	headInput := &s3.HeadObjectInput{
+		Bucket: aws.String(s.name),
+		Key:    aws.String(rp),
+	}
+	if opt.HasExceptedBucketOwner {
+		headInput.ExpectedBucketOwner = &opt.ExceptedBucketOwner
+	}
+	if opt.HasServerSideEncryptionCustomerAlgorithm {
+		headInput.SSECustomerAlgorithm, headInput.SSECustomerKey, headInput.SSECustomerKeyMD5, err = calculateEncryptionHeaders(opt.ServerSideEncryptionCustomerAlgorithm, opt.ServerSideEncryptionCustomerKey)
+		if err != nil {
+			return
+		}
+	}
+	head, err := s.service.HeadObject(ctx, headInput)
+	if err != nil {
+		return
+	}
+	// S3 doesn't return the storage class for STANDARD objects.
+	if current := string(head.StorageClass); current == storageClass || (current == "" && storageClass == string(StorageClassStandard)) {
+		return head.ContentLength, true, nil
+	}
+
+	if head.ContentLength <= copySizeMaximum {
+		err = s.copyObjectWithStorageClass(ctx, rp, storageClass, head, opt)
+	} else {
+		err = s.multipartCopyObjectWithStorageClass(ctx, rp, storageClass, head, opt)
+	}
+	if err != nil {
+		return
+	}
+	return head.ContentLength, false, nil
+}
+
+func (s *Storage) copyObjectWithStorageClass(ctx context.Context, rp string, storageClass string, head *s3.HeadObjectOutput, opt pairStorageTransition) (err error) {
+	input := &s3.CopyObjectInput{
+		Bucket:     aws.String(s.name),
+		Key:        aws.String(rp),
+		CopySource: aws.String(formatCopySource(s.name, rp)),
+		// Make sure the object is not changed since HeadObject.
+		CopySourceIfMatch: head.ETag,
+		MetadataDirective: s3types.MetadataDirectiveCopy,
+		TaggingDirective:  s3types.TaggingDirectiveCopy,
+		StorageClass:      s3types.StorageClass(storageClass),
+		// CopyObject will use the bucket's default encryption instead of the source's, so we need to set it explicitly.
+		ServerSideEncryption: head.ServerSideEncryption,
+		SSEKMSKeyId:          head.SSEKMSKeyId,
+		BucketKeyEnabled:     head.BucketKeyEnabled,
+	}
+	if opt.HasExceptedBucketOwner {
+		input.ExpectedBucketOwner = &opt.ExceptedBucketOwner
+		input.ExpectedSourceBucketOwner = &opt.ExceptedBucketOwner
+	}
+	if opt.HasServerSideEncryptionCustomerAlgorithm {
+		input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5, err = calculateEncryptionHeaders(opt.ServerSideEncryptionCustomerAlgorithm, opt.ServerSideEncryptionCustomerKey)
+		if err != nil {
+			return
+		}
+		input.CopySourceSSECustomerAlgorithm, input.CopySourceSSECustomerKey, input.CopySourceSSECustomerKeyMD5, err = calculateEncryptionHeaders(opt.ServerSideEncryptionCustomerAlgorithm, opt.ServerSideEncryptionCustomerKey)
+		if err != nil {
+			return
+		}
+	}
+	_, err = s.service.CopyObject(ctx, input)
+	return err
+}
+
+func (s *Storage) multipartCopyObjectWithStorageClass(ctx context.Context, rp string, storageClass string, head *s3.HeadObjectOutput, opt pairStorageTransition) (err error) {
+	// Multipart upload can't copy tags from the source, so we have to read them first.
+	tags, err := s.getObjectTagging(ctx, s.getRelPath(rp), pairStorageObjectTagging{
+		HasExceptedBucketOwner: opt.HasExceptedBucketOwner,
+		ExceptedBucketOwner:    opt.ExceptedBucketOwner,
+	})
+	if err != nil {
+		return
+	}
+
+	createInput := &s3.CreateMultipartUploadInput{
+		Bucket:                  aws.String(s.name),
+		Key:                     aws.String(rp),
+		StorageClass:            s3types.StorageClass(storageClass),
+		Metadata:                head.Metadata,
+		ContentType:             head.ContentType,
+		CacheControl:            head.CacheControl,
+		ContentDisposition:      head.ContentDisposition,
+		ContentEncoding:         head.ContentEncoding,
+		ContentLanguage:         head.ContentLanguage,
+		Expires:                 head.Expires,
+		WebsiteRedirectLocation: head.WebsiteRedirectLocation,
+		ServerSideEncryption:    head.ServerSideEncryption,
+		SSEKMSKeyId:             head.SSEKMSKeyId,
+		BucketKeyEnabled:        head.BucketKeyEnabled,
+	}
+	if len(tags) > 0 {
+		createInput.Tagging = formatTagging(tags)
+	}
+	if opt.HasExceptedBucketOwner {
+		createInput.ExpectedBucketOwner = &opt.ExceptedBucketOwner
+	}
+	if opt.HasServerSideEncryptionCustomerAlgorithm {
+		createInput.SSECustomerAlgorithm, createInput.SSECustomerKey, createInput.SSECustomerKeyMD5, err = calculateEncryptionHeaders(opt.ServerSideEncryptionCustomerAlgorithm, opt.ServerSideEncryptionCustomerKey)
+		if err != nil {
+			return
+		}
+	}
+	createOutput, err := s.service.CreateMultipartUpload(ctx, createInput)
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if err == nil {
+			return
+		}
+		// Abort the multipart upload to avoid leaving parts behind, the original error is more useful.
+		abortInput := &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(s.name),
+			Key:      aws.String(rp),
+			UploadId: createOutput.UploadId,
+		}
+		if opt.HasExceptedBucketOwner {
+			abortInput.ExpectedBucketOwner = &opt.ExceptedBucketOwner
+		}
+		_, _ = s.service.AbortMultipartUpload(ctx, abortInput)
+	}()
+
+	partSize := calculatePartSize(head.ContentLength, copyPartSize)
+	var completedParts []s3types.CompletedPart
+	for offset, number := int64(0), int32(1); offset < head.ContentLength; offset, number = offset+partSize, number+1 {
+		end := offset + partSize - 1
+		if end >= head.ContentLength {
+			end = head.ContentLength - 1
+		}
+
+		input := &s3.UploadPartCopyInput{
+			Bucket:            aws.String(s.name),
+			Key:               aws.String(rp),
+			UploadId:          createOutput.UploadId,
+			PartNumber:        number,
+			CopySource:        aws.String(formatCopySource(s.name, rp)),
+			CopySourceRange:   aws.String(fmt.Sprintf("bytes=%d-%d", offset, end)),
+			CopySourceIfMatch: head.ETag,
+		}
+		if opt.HasExceptedBucketOwner {
+			input.ExpectedBucketOwner = &opt.ExceptedBucketOwner
+			input.ExpectedSourceBucketOwner = &opt.ExceptedBucketOwner
+		}
+		if opt.HasServerSideEncryptionCustomerAlgorithm {
+			input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5, err = calculateEncryptionHeaders(opt.ServerSideEncryptionCustomerAlgorithm, opt.ServerSideEncryptionCustomerKey)
+			if err != nil {
+				return
+			}
+			input.CopySourceSSECustomerAlgorithm, input.CopySourceSSECustomerKey, input.CopySourceSSECustomerKeyMD5, err = calculateEncryptionHeaders(opt.ServerSideEncryptionCustomerAlgorithm, opt.ServerSideEncryptionCustomerKey)
+			if err != nil {
+				return
+			}
+		}
+		output, err := s.service.UploadPartCopy(ctx, input)
+		if err != nil {
+			return err
+		}
+		completedParts = append(completedParts, s3types.CompletedPart{
+			ETag:       output.CopyPartResult.ETag,
+			PartNumber: number,
+		})
+	}
+
+	completeInput := &s3.CompleteMultipartUploadInput{
+		Bucket:   aws.String(s.name),
+		Key:      aws.String(rp),
+		UploadId: createOutput.UploadId,
+		MultipartUpload: &s3types.CompletedMultipartUpload{
+			Parts: completedParts,
+		},
+	}
+	if opt.HasExceptedBucketOwner {
+		completeInput.ExpectedBucketOwner = &opt.ExceptedBucketOwner
+	}
+	_, err = s.service.CompleteMultipartUpload(ctx, completeInput)
+	return err
+}
+
+// formatCopySource will format the x-amz-copy-source header which should be URL-encoded.
+func formatCopySource(bucket, key string) string {
+	return url.PathEscape(bucket + "/" + key)
+}
@@ -0,0 +1,232 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/beyondstorage/go-storage/v4/services"
+	. "github.com/beyondstorage/go-storage/v4/types"
+)
+
+// defaultUsageDepth is the default depth of prefixes to group usage by, which means top-level prefixes.
+const defaultUsageDepth = 1
+
+// UsageStats is the count and total size of objects.
+type UsageStats struct {
+	Objects int64
+	Bytes   int64
+}
+
+func (u *UsageStats) add(size int64) {
+	u.Objects++
+	u.Bytes += size
+}
+
+// UsageGroup is the usage of objects in the same group.
+type UsageGroup struct {
+	// Total is the usage of all objects in the group.
+	Total UsageStats
+	// StorageClasses is the usage of objects grouped by StorageClass.
+	StorageClasses map[string]UsageStats
+	// Multiparts is the usage of incomplete multipart uploads, Objects is the count of uploads
+	// and Bytes is the total size of their uploaded parts.
+	Multiparts UsageStats
+}
+
+func newUsageGroup() *UsageGroup {
+	return &UsageGroup{StorageClasses: make(map[string]UsageStats)}
+}
+
+func (g *UsageGroup) addObject(storageClass string, size int64) {
+	g.Total.add(size)
+
+	stats := g.StorageClasses[storageClass]
+	stats.add(size)
+	g.StorageClasses[storageClass] = stats
+}
+
+// UsageSummary is the usage summary of Usage.
+type UsageSummary struct {
+	// UsageGroup is the usage of all objects under the path.
+	UsageGroup
+	// Prefixes is the usage grouped by prefixes of the depth specified by WithUsageDepth.
+	//
+	// Prefixes are paths ending with "/", objects not deep enough will be grouped into the path itself.
+	Prefixes map[string]*UsageGroup
+}
+
+func (u *UsageSummary) prefix(p string) *UsageGroup {
+	g, ok := u.Prefixes[p]
+	if !ok {
+		g = newUsageGroup()
+		u.Prefixes[p] = g
+	}
+	return g
+}
+
+// pairStorageUsage is the parsed struct for Usage.
+type pairStorageUsage struct {
+	pairs []Pair
+	// Optional pairs
+	HasExceptedBucketOwner bool
+	ExceptedBucketOwner    string
+	HasUsageDepth          bool
+	UsageDepth             int
+}
+
+func (s *Storage) parsePairStorageUsage(opts []Pair) (pairStorageUsage, error) {
+	result := pairStorageUsage{pairs: opts}
+
+	for _, v := range opts {
+		switch v.Key {
+		case "excepted_bucket_owner":
+			if result.HasExceptedBucketOwner {
+				continue
+			}
+			result.HasExceptedBucketOwner = true
+			result.ExceptedBucketOwner = v.Value.(string)
+		case "usage_depth":
+			if result.HasUsageDepth {
+				continue
+			}
+			result.HasUsageDepth = true
+			result.UsageDepth = v.Value.(int)
+		default:
+			return pairStorageUsage{}, services.PairUnsupportedError{Pair: v}
+		}
+	}
+
+	return result, nil
+}
+
+// Usage will summarize the count and total size of objects under the dir path.
+//
+// The usage will be grouped by StorageClass and by prefixes, use WithUsageDepth to specify the depth of
+// prefixes relative to path, 1 by default. Incomplete multipart uploads and the size of their uploaded
+// parts will also be counted, which are not visible in list but still billed.
+func (s *Storage) Usage(path string, pairs ...Pair) (summary *UsageSummary, err error) {
+	ctx := context.Background()
+	return s.UsageWithContext(ctx, path, pairs...)
+}
+
+// UsageWithContext will summarize the count and total size of objects under the dir path.
+//
+// The usage will be grouped by StorageClass and by prefixes, use WithUsageDepth to specify the depth of
+// prefixes relative to path, 1 by default. Incomplete multipart uploads and the size of their uploaded
+// parts will also be counted, which are not visible in list but still billed.
+func (s *Storage) UsageWithContext(ctx context.Context, path string, pairs ...Pair) (summary *UsageSummary, err error) {
+	defer func() {
+		err = s.formatError("usage", err, path)
+	}()
+
+	opt, err := s.parsePairStorageUsage(pairs)
+	if err != nil {
+		return
+	}
+	return s.usage(ctx, strings.ReplaceAll(path, "\\", "/"), opt)
+}
+
+func (s *Storage) usage(ctx context.Context, path string, opt pairStorageUsage) (summary *UsageSummary, err error) {
+	depth := defaultUsageDepth
+	if opt.HasUsageDepth && opt.UsageDepth >= 0 {
+		depth = opt.UsageDepth
+	}
+	// Summarize the path as a dir, so that "dir" will not match "dir2/".
+	if path != "" && !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+
+	summary = &UsageSummary{
+		UsageGroup: *newUsageGroup(),
+		Prefixes:   make(map[string]*UsageGroup),
+	}
+
+	input := &objectPageStatus{
+		maxKeys: listMaxKeysMaximum,
+		prefix:  s.getAbsPath(path),
+	}
+	if opt.HasExceptedBucketOwner {
+		input.expectedBucketOwner = opt.ExceptedBucketOwner
+	}
+	for {
+		// Use the list result directly, as getters of objects returned by list will trigger stat.
+		contents, _, truncated, err := s.listObjects(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range contents {
+			storageClass := string(v.StorageClass)
+
+			summary.addObject(storageClass, v.Size)
+			summary.prefix(usagePrefix(path, s.getRelPath(aws.ToString(v.Key)), depth)).addObject(storageClass, v.Size)
+		}
+		if !truncated {
+			break
+		}
+	}
+
+	it, err := s.list(ctx, path, pairStorageList{
+		HasListMode:            true,
+		ListMode:               ListModePart,
+		HasExceptedBucketOwner: opt.HasExceptedBucketOwner,
+		ExceptedBucketOwner:    opt.ExceptedBucketOwner,
+	})
+	if err != nil {
+		return
+	}
+	for {
+		o, err := it.Next()
+		if errors.Is(err, IterateDone) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		size, err := s.multipartSize(ctx, o, opt)
+		if err != nil {
+			return nil, err
+		}
+
+		summary.Multiparts.add(size)
+		summary.prefix(usagePrefix(path, o.Path, depth)).Multiparts.add(size)
+	}
+	return summary, nil
+}
+
+// multipartSize will return the total size of uploaded parts of the multipart upload o.
+func (s *Storage) multipartSize(ctx context.Context, o *Object, opt pairStorageUsage) (size int64, err error) {
+	it, err := s.listMultipart(ctx, o, pairStorageListMultipart{
+		HasExceptedBucketOwner: opt.HasExceptedBucketOwner,
+		ExceptedBucketOwner:    opt.ExceptedBucketOwner,
+	})
+	if err != nil {
+		return
+	}
+	for {
+		p, err := it.Next()
+		if errors.Is(err, IterateDone) {
+			return size, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		size += p.Size
+	}
+}
+
+// usagePrefix will return the prefix of p relative to the dir path with depth segments.
+func usagePrefix(path, p string, depth int) string {
+	if depth == 0 {
+		return path
+	}
+	segments := strings.Split(strings.TrimPrefix(p, path), "/")
+	// The last segment is the name of the object, which is not a prefix.
+	if len(segments)-1 < depth {
+		return path
+	}
+	return path + strings.Join(segments[:depth], "/") + "/"
+}
@@ -6,7 +6,10 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	signerv4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
@@ -17,6 +20,7 @@ import (
 
 	"github.com/aws/smithy-go"
 	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 	"github.com/beyondstorage/go-endpoint"
 	ps "github.com/beyondstorage/go-storage/v4/pairs"
 	"github.com/beyondstorage/go-storage/v4/pkg/credential"
@@ -32,6 +36,8 @@ type Service struct {
 	defaultPairs DefaultServicePairs
 	features     ServiceFeatures
 
+	forcePathStyle bool
+
 	typ.UnimplementedServicer
 }
 
@@ -47,6 +53,12 @@ type Storage struct {
 	name    string
 	workDir string
 
+	// querySignScheme and querySignHost will replace the scheme and host of presigned URLs if set.
+	querySignScheme string
+	querySignHost   string
+	// useListObjectsV1 will make list use ListObjects (v1) instead of ListObjectsV2.
+	useListObjectsV1 bool
+
 	defaultPairs DefaultStoragePairs
 	features     StorageFeatures
 
@@ -140,8 +152,9 @@ func newServicer(pairs ...typ.Pair) (srv *Service, err error) {
 	}
 
 	srv = &Service{
-		cfg:     &cfg,
-		service: newS3Service(&cfg),
+		cfg:            &cfg,
+		service:        newS3Service(&cfg, opt.ForcePathStyle),
+		forcePathStyle: opt.ForcePathStyle,
 	}
 
 	if opt.HasDefaultServicePairs {
@@ -180,31 +193,50 @@ const (
 )
 
 func formatError(err error) error {
-	if _, ok := err.(services.InternalError); ok {
+	// Errors defined in go-storage could be wrapped with more details, so we should check the whole chain.
+	var ie services.InternalError
+	if errors.As(err, &ie) {
 		return err
 	}
 
-	e := &smithy.GenericAPIError{}
+	// Errors modeled by the SDK like *s3types.NoSuchKey are not GenericAPIError, so match the interface instead.
+	var e smithy.APIError
 	if ok := errors.As(err, &e); !ok {
 		return fmt.Errorf("%w: %v", services.ErrUnexpected, err)
 	}
 
-	switch e.Code {
+	switch e.ErrorCode() {
 	// AWS SDK will use status code to generate awserr.Error, so "NotFound" should also be supported.
 	case "NoSuchKey", "NotFound":
 		return fmt.Errorf("%w: %v", services.ErrObjectNotExist, err)
 	case "AccessDenied":
+		// S3 returns AccessDenied for operations blocked by Object Lock, we can only tell them by message.
+		if strings.Contains(strings.ToLower(e.ErrorMessage()), "object lock") {
+			return fmt.Errorf("%w: %v", ErrObjectLocked, err)
+		}
 		return fmt.Errorf("%w: %v", services.ErrPermissionDenied, err)
+	// S3 will return 304 Not Modified without body, so the code is derived from the status code.
+	case "NotModified":
+		return fmt.Errorf("%w: %v", ErrObjectNotModified, err)
+	// S3 will return 409 ConditionalRequestConflict while there is a conflicting conditional write in progress.
+	case "PreconditionFailed", "ConditionalRequestConflict":
+		return fmt.Errorf("%w: %v", ErrPreconditionFailed, err)
+	// InvalidObjectState of restore means the object is not archived, which is handled by restoreObject.
+	case "InvalidObjectState":
+		return fmt.Errorf("%w: %v", ErrObjectArchived, err)
+	case "RestoreAlreadyInProgress":
+		return fmt.Errorf("%w: %v", ErrRestoreInProgress, err)
 	default:
 		return fmt.Errorf("%w: %v", services.ErrUnexpected, err)
 	}
 }
 
-func newS3Service(cfgs *aws.Config) (srv *s3.Client) {
+func newS3Service(cfgs *aws.Config, forcePathStyle bool) (srv *s3.Client) {
 	// S3 will calculate payload's content-sha256 by default, we change this behavior for following reasons:
 	// - To support uploading content without seek support: stdin, bytes.Reader
 	// - To allow user decide when to calculate the hash, especially for big files
 	srv = s3.NewFromConfig(*cfgs, func(options *s3.Options) {
+		options.UsePathStyle = forcePathStyle
 		options.APIOptions = append(options.APIOptions,
 			func(stack *middleware.Stack) error {
 				// With removing PayloadSHA256 and adding UnsignedPayload, signer will set "X-Amz-Content-Sha256" to "UNSIGNED-PAYLOAD"
@@ -230,7 +262,7 @@ func (s *Service) newStorage(pairs ...typ.Pair) (st *Storage, err error) {
 	}
 
 	st = &Storage{
-		service: newS3Service(s.cfg),
+		service: newS3Service(s.cfg, s.forcePathStyle),
 		name:    optStorage.Name,
 		workDir: "/",
 	}
@@ -244,9 +276,73 @@ func (s *Service) newStorage(pairs ...typ.Pair) (st *Storage, err error) {
 	if optStorage.HasWorkDir {
 		st.workDir = optStorage.WorkDir
 	}
+	if optStorage.HasUseListObjectsV1 {
+		st.useListObjectsV1 = optStorage.UseListObjectsV1
+	}
+	if optStorage.HasQuerySignEndpoint {
+		ep, err := endpoint.Parse(optStorage.QuerySignEndpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		var rawURL string
+		switch ep.Protocol() {
+		case endpoint.ProtocolHTTP:
+			rawURL, _, _ = ep.HTTP()
+		case endpoint.ProtocolHTTPS:
+			rawURL, _, _ = ep.HTTPS()
+		default:
+			return nil, services.PairUnsupportedError{Pair: WithQuerySignEndpoint(optStorage.QuerySignEndpoint)}
+		}
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		st.querySignScheme = u.Scheme
+		st.querySignHost = u.Host
+	}
 	return st, nil
 }
 
+// newPresignClient will create a presign client which signs requests with given expire.
+func (s *Storage) newPresignClient(expire time.Duration) *s3.PresignClient {
+	return s3.NewPresignClient(s.service, func(options *s3.PresignOptions) {
+		options.Expires = expire
+
+		if s.querySignHost == "" {
+			return
+		}
+		options.ClientOptions = append(options.ClientOptions, func(o *s3.Options) {
+			o.APIOptions = append(o.APIOptions, s.addQuerySignEndpointMiddleware)
+		})
+	})
+}
+
+// addQuerySignEndpointMiddleware will replace the request's scheme and host before signing.
+//
+// The host is part of the SigV4 signature, so it must be replaced before the request gets signed
+// in the finalize step, otherwise the presigned URL will be rejected by S3.
+func (s *Storage) addQuerySignEndpointMiddleware(stack *middleware.Stack) error {
+	// PresignClient applies ClientOptions both while creating the client and while invoking the operation,
+	// so we need to make sure the middleware will only be added once.
+	if _, ok := stack.Build.Get("QuerySignEndpoint"); ok {
+		return nil
+	}
+	return stack.Build.Add(middleware.BuildMiddlewareFunc("QuerySignEndpoint", func(
+		ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+	) (out middleware.BuildOutput, metadata middleware.Metadata, err error) {
+		req, ok := in.Request.(*smithyhttp.Request)
+		if !ok {
+			return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+		}
+
+		req.URL.Scheme = s.querySignScheme
+		req.URL.Host = s.querySignHost
+		req.Host = s.querySignHost
+		return next.HandleBuild(ctx, in)
+	}), middleware.After)
+}
+
 func (s *Service) formatError(op string, err error, name string) error {
 	if err == nil {
 		return nil
@@ -285,12 +381,21 @@ func (s *Storage) formatError(op string, err error, path ...string) error {
 	}
 }
 
-func (s *Storage) formatFileObject(v s3types.Object) (o *typ.Object, err error) {
-	o = s.newObject(false)
+func (s *Storage) formatFileObject(v s3types.Object, input *objectPageStatus) (o *typ.Object, err error) {
+	if v.Owner != nil {
+		// Owner is only returned by list, keep it for the lazy stat of this object.
+		o = typ.NewObject(&ownerStorager{
+			Storage:          s,
+			ownerID:          aws.ToString(v.Owner.ID),
+			ownerDisplayName: aws.ToString(v.Owner.DisplayName),
+		}, false)
+	} else {
+		o = s.newObject(false)
+	}
 	o.ID = *v.Key
 	o.Path = s.getRelPath(*v.Key)
 	// If you have enabled virtual link, you will not get the accurate object type.
-	// If you want to get the exact object mode, please use `stat`
+	// If you want to get the exact object mode, please use `stat` or list with `resolve_mode`.
 	o.Mode |= typ.ModeRead
 
 	o.SetContentLength(v.Size)
@@ -303,15 +408,292 @@ func (s *Storage) formatFileObject(v s3types.Object) (o *typ.Object, err error)
 	var sm ObjectSystemMetadata
 	//v.StorageClass's type is s3types.ObjectStorageClass, which is equivalent to string
 	sm.StorageClass = string(v.StorageClass)
+	if v.Owner != nil {
+		sm.OwnerID = aws.ToString(v.Owner.ID)
+		sm.OwnerDisplayName = aws.ToString(v.Owner.DisplayName)
+	}
 	o.SetSystemMetadata(sm)
 
 	return
 }
 
+// formatConditionalWriteOptions will set If-Match and If-None-Match for PutObject and CompleteMultipartUpload,
+// S3 only supports "*" for If-None-Match of writes.
+func formatConditionalWriteOptions(hasIfMatch bool, ifMatch string, hasIfNoneMatch bool, ifNoneMatch string) (optFns []func(*s3.Options), err error) {
+	if hasIfNoneMatch && ifNoneMatch != "*" {
+		return nil, services.PairUnsupportedError{Pair: WithIfNoneMatch(ifNoneMatch)}
+	}
+	if hasIfMatch {
+		optFns = append(optFns, withHeader("If-Match", ifMatch))
+	}
+	if hasIfNoneMatch {
+		optFns = append(optFns, withHeader("If-None-Match", ifNoneMatch))
+	}
+	return optFns, nil
+}
+
+// withHeader will set the header of the request, which is used for headers that haven't been supported by the SDK.
+// The SDK doesn't support conditional writes yet, so If-Match and If-None-Match of writes are set by this.
+func withHeader(key, value string) func(*s3.Options) {
+	return func(options *s3.Options) {
+		options.APIOptions = append(options.APIOptions, smithyhttp.SetHeaderValue(key, value))
+	}
+}
+
+// formatTagging will encode tags as URL query parameters, which is required by `x-amz-tagging`.
+func formatTagging(tags map[string]string) *string {
+	values := url.Values{}
+	for k, v := range tags {
+		values.Set(k, v)
+	}
+	return aws.String(values.Encode())
+}
+
+// listObjects will list a page of objects with ListObjectsV2, or ListObjects (v1) if useListObjectsV1 is set,
+// and update the status for the next page if the result is truncated.
+func (s *Storage) listObjects(ctx context.Context, input *objectPageStatus) (contents []s3types.Object, prefixes []s3types.CommonPrefix, truncated bool, err error) {
+	if s.useListObjectsV1 {
+		return s.listObjectsV1(ctx, input)
+	}
+
+	listInput := &s3.ListObjectsV2Input{
+		Bucket:            &s.name,
+		MaxKeys:           int32(input.maxKeys),
+		ContinuationToken: input.getServiceContinuationToken(),
+		Prefix:            &input.prefix,
+		FetchOwner:        input.fetchOwner,
+	}
+	if input.delimiter != "" {
+		listInput.Delimiter = &input.delimiter
+	}
+	if input.expectedBucketOwner != "" {
+		listInput.ExpectedBucketOwner = &input.expectedBucketOwner
+	}
+	if input.startAfter != "" {
+		listInput.StartAfter = &input.startAfter
+	}
+	if input.encodingType != "" {
+		listInput.EncodingType = s3types.EncodingType(input.encodingType)
+	}
+	output, err := s.service.ListObjectsV2(ctx, listInput)
+	if err != nil {
+		return
+	}
+
+	if output.IsTruncated {
+		input.continuationToken = aws.ToString(output.NextContinuationToken)
+	}
+	return output.Contents, output.CommonPrefixes, output.IsTruncated, nil
+}
+
+// listObjectsV1 will list a page of objects with ListObjects (v1), the marker will be stored as continuation token.
+//
+// ListObjects always returns the owner, and there is no StartAfter in v1, so we use it as the first marker.
+func (s *Storage) listObjectsV1(ctx context.Context, input *objectPageStatus) (contents []s3types.Object, prefixes []s3types.CommonPrefix, truncated bool, err error) {
+	listInput := &s3.ListObjectsInput{
+		Bucket:  &s.name,
+		MaxKeys: int32(input.maxKeys),
+		Prefix:  &input.prefix,
+	}
+	marker := input.continuationToken
+	if marker == "" {
+		marker = input.startAfter
+	}
+	if marker != "" {
+		listInput.Marker = &marker
+	}
+	if input.delimiter != "" {
+		listInput.Delimiter = &input.delimiter
+	}
+	if input.expectedBucketOwner != "" {
+		listInput.ExpectedBucketOwner = &input.expectedBucketOwner
+	}
+	if input.encodingType != "" {
+		listInput.EncodingType = s3types.EncodingType(input.encodingType)
+	}
+	output, err := s.service.ListObjects(ctx, listInput)
+	if err != nil {
+		return
+	}
+
+	if output.IsTruncated {
+		// NextMarker is only returned while delimiter is specified, use the last key or prefix instead.
+		next := aws.ToString(output.NextMarker)
+		if next == "" {
+			if n := len(output.Contents); n > 0 {
+				next = aws.ToString(output.Contents[n-1].Key)
+			}
+			if n := len(output.CommonPrefixes); n > 0 && aws.ToString(output.CommonPrefixes[n-1].Prefix) > next {
+				next = aws.ToString(output.CommonPrefixes[n-1].Prefix)
+			}
+		}
+		input.continuationToken, err = decodeKey(next, input.encodingType)
+		if err != nil {
+			return
+		}
+	}
+	return output.Contents, output.CommonPrefixes, output.IsTruncated, nil
+}
+
+// defaultResolveModeConcurrency is the default concurrency of HEAD requests used to resolve modes in list.
+const defaultResolveModeConcurrency = 8
+
+// resolveObjectModes will detect the real mode of objects returned by list.
+//
+// Dir markers created by create_dir are detected by their trailing "/". Links are detected by stat,
+// and as links created by create_link are always empty, only empty objects will be checked.
+func (s *Storage) resolveObjectModes(ctx context.Context, contents []s3types.Object, objects []*typ.Object, input *objectPageStatus) (err error) {
+	var indexes []int
+	for i, o := range objects {
+		if s.features.VirtualDir && strings.HasSuffix(o.ID, "/") {
+			// Replace with a done object, otherwise the mode will be overwritten by stat.
+			d := s.newObject(true)
+			d.ID = o.ID
+			d.Path = o.Path
+			d.Mode |= typ.ModeDir
+			objects[i] = d
+			continue
+		}
+		// Don't use o.GetContentLength here, which will trigger stat on the object.
+		if s.features.VirtualLink && contents[i].Size == 0 {
+			indexes = append(indexes, i)
+		}
+	}
+
+	var (
+		wg   sync.WaitGroup
+		once sync.Once
+		ch   = make(chan int)
+	)
+	for i := 0; i < input.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for idx := range ch {
+				o, serr := s.stat(ctx, objects[idx].Path, pairStorageStat{
+					HasExceptedBucketOwner: input.expectedBucketOwner != "",
+					ExceptedBucketOwner:    input.expectedBucketOwner,
+				})
+				if serr != nil {
+					// The object could be deleted after list, keep it as is.
+					if errors.Is(formatError(serr), services.ErrObjectNotExist) {
+						continue
+					}
+					once.Do(func() {
+						err = serr
+					})
+					continue
+				}
+				// Every goroutine writes to different index, so it's safe without lock.
+				objects[idx] = o
+			}
+		}()
+	}
+	for _, idx := range indexes {
+		ch <- idx
+	}
+	close(ch)
+	wg.Wait()
+
+	return err
+}
+
+// statImplicitDir will check whether the dir rp exists implicitly by listing one key under it,
+// which allows dirs without marker objects, for example dirs created by create_dir with implicit_dir.
+func (s *Storage) statImplicitDir(ctx context.Context, path string, rp string, opt pairStorageStat) (o *typ.Object, exist bool, err error) {
+	input := &objectPageStatus{
+		maxKeys: 1,
+		prefix:  rp,
+	}
+	if opt.HasExceptedBucketOwner {
+		input.expectedBucketOwner = opt.ExceptedBucketOwner
+	}
+	contents, prefixes, _, err := s.listObjects(ctx, input)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(contents) == 0 && len(prefixes) == 0 {
+		return nil, false, nil
+	}
+
+	o = s.newObject(true)
+	o.ID = rp
+	o.Path = path
+	o.Mode |= typ.ModeDir
+	return o, true, nil
+}
+
+// abortMultipart will abort the multipart upload o, errors will be ignored as the original error is more useful.
+func (s *Storage) abortMultipart(ctx context.Context, o *typ.Object) {
+	// Delete with multipart id will also delete the object, so we abort the upload directly.
+	_, _ = s.service.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.name),
+		Key:      aws.String(o.ID),
+		UploadId: aws.String(o.MustGetMultipartID()),
+	})
+}
+
+// formatDirPath will add "/" at the end of non-empty path, so that the path will be treated as a dir
+// in prefix listing, which means "dir" will not match "dir2/".
+func formatDirPath(path string) string {
+	if path != "" && !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+	return path
+}
+
+// decodeKey will decode the key returned by list with the encoding type.
+func decodeKey(key string, encodingType string) (string, error) {
+	if encodingType != EncodingTypeURL {
+		return key, nil
+	}
+	return url.QueryUnescape(key)
+}
+
+// formatUserMetadata will filter out the metadata used internally, and return the user-defined metadata.
+func formatUserMetadata(metadata map[string]string) map[string]string {
+	um := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		if k == metadataLinkTargetHeader {
+			continue
+		}
+		um[k] = v
+	}
+	return um
+}
+
 func (s *Storage) newObject(done bool) *typ.Object {
 	return typ.NewObject(s, done)
 }
 
+// ownerStorager will keep the owner returned by list for objects which are stat lazily,
+// as HeadObject doesn't return the owner.
+type ownerStorager struct {
+	*Storage
+
+	ownerID          string
+	ownerDisplayName string
+}
+
+func (s *ownerStorager) Stat(path string, pairs ...typ.Pair) (o *typ.Object, err error) {
+	o, err = s.Storage.Stat(path, pairs...)
+	if err != nil {
+		return
+	}
+
+	sm := GetObjectSystemMetadata(o)
+	sm.OwnerID = s.ownerID
+	sm.OwnerDisplayName = s.ownerDisplayName
+	o.SetSystemMetadata(sm)
+	return
+}
+
+// All available encoding types of list are listed here.
+const (
+	EncodingTypeURL = string(s3types.EncodingTypeUrl)
+)
+
 // All available server side algorithm are listed here.
 const (
 	ServerSideEncryptionAes256 = s3types.ServerSideEncryptionAes256
@@ -340,12 +722,37 @@ const (
 	multipartSizeMinimum = 5 * 1024 * 1024
 )
 
+// calculatePartSize will return the part size used to upload or copy an object with multipart,
+// which will be enlarged from partSize to keep the part count under multipartNumberMaximum.
+func calculatePartSize(size int64, partSize int64) int64 {
+	if size > partSize*multipartNumberMaximum {
+		partSize = (size + multipartNumberMaximum - 1) / multipartNumberMaximum
+	}
+	return partSize
+}
+
 const (
 	// writeSizeMaximum is the maximum size for each object with a single PUT operation, 5GB.
 	// ref: https://docs.aws.amazon.com/AmazonS3/latest/userguide/upload-objects.html
 	writeSizeMaximum = 5 * 1024 * 1024 * 1024
 )
 
+const (
+	// listMaxKeysDefault is the default number of objects returned in each page of list.
+	listMaxKeysDefault = 200
+	// listMaxKeysMaximum is the maximum number of objects returned in each page of list.
+	// ref: https://docs.aws.amazon.com/AmazonS3/latest/API/API_ListObjectsV2.html
+	listMaxKeysMaximum = 1000
+)
+
+const (
+	// copySizeMaximum is the maximum size for each object with a single COPY operation, 5GB.
+	// ref: https://docs.aws.amazon.com/AmazonS3/latest/userguide/copy-object.html
+	copySizeMaximum = 5 * 1024 * 1024 * 1024
+	// copyPartSize is the default part size used in multipart copy.
+	copyPartSize = 512 * 1024 * 1024
+)
+
 func (s *Storage) formatGetObjectInput(path string, opt pairStorageRead) (input *s3.GetObjectInput, err error) {
 	rp := s.getAbsPath(path)
 
@@ -371,6 +778,78 @@ func (s *Storage) formatGetObjectInput(path string, opt pairStorageRead) (input
 			return nil, err
 		}
 	}
+	if opt.HasResponseCacheControl {
+		input.ResponseCacheControl = &opt.ResponseCacheControl
+	}
+	if opt.HasResponseContentDisposition {
+		input.ResponseContentDisposition = &opt.ResponseContentDisposition
+	}
+	if opt.HasResponseContentEncoding {
+		input.ResponseContentEncoding = &opt.ResponseContentEncoding
+	}
+	if opt.HasResponseContentLanguage {
+		input.ResponseContentLanguage = &opt.ResponseContentLanguage
+	}
+	if opt.HasResponseContentType {
+		input.ResponseContentType = &opt.ResponseContentType
+	}
+	if opt.HasResponseExpires {
+		input.ResponseExpires = &opt.ResponseExpires
+	}
+	if opt.HasIfMatch {
+		input.IfMatch = &opt.IfMatch
+	}
+	if opt.HasIfModifiedSince {
+		input.IfModifiedSince = &opt.IfModifiedSince
+	}
+	if opt.HasIfNoneMatch {
+		input.IfNoneMatch = &opt.IfNoneMatch
+	}
+	if opt.HasIfUnmodifiedSince {
+		input.IfUnmodifiedSince = &opt.IfUnmodifiedSince
+	}
+
+	return
+}
+
+func (s *Storage) formatHeadObjectInput(path string, opt pairStorageStat) (input *s3.HeadObjectInput, err error) {
+	rp := s.getAbsPath(path)
+
+	if opt.HasObjectMode && opt.ObjectMode.IsDir() {
+		if !s.features.VirtualDir {
+			err = services.PairUnsupportedError{Pair: ps.WithObjectMode(opt.ObjectMode)}
+			return nil, err
+		}
+
+		rp += "/"
+	}
+
+	input = &s3.HeadObjectInput{
+		Bucket: aws.String(s.name),
+		Key:    aws.String(rp),
+	}
+
+	if opt.HasExceptedBucketOwner {
+		input.ExpectedBucketOwner = &opt.ExceptedBucketOwner
+	}
+	if opt.HasServerSideEncryptionCustomerAlgorithm {
+		input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5, err = calculateEncryptionHeaders(opt.ServerSideEncryptionCustomerAlgorithm, opt.ServerSideEncryptionCustomerKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if opt.HasIfMatch {
+		input.IfMatch = &opt.IfMatch
+	}
+	if opt.HasIfModifiedSince {
+		input.IfModifiedSince = &opt.IfModifiedSince
+	}
+	if opt.HasIfNoneMatch {
+		input.IfNoneMatch = &opt.IfNoneMatch
+	}
+	if opt.HasIfUnmodifiedSince {
+		input.IfUnmodifiedSince = &opt.IfUnmodifiedSince
+	}
 
 	return
 }
@@ -387,6 +866,9 @@ func (s *Storage) formatPutObjectInput(path string, size int64, opt pairStorageW
 	if opt.HasContentMd5 {
 		input.ContentMD5 = &opt.ContentMd5
 	}
+	if opt.HasContentType {
+		input.ContentType = &opt.ContentType
+	}
 	if opt.HasStorageClass {
 		input.StorageClass = s3types.StorageClass(opt.StorageClass)
 	}
@@ -412,6 +894,51 @@ func (s *Storage) formatPutObjectInput(path string, size int64, opt pairStorageW
 	if opt.HasServerSideEncryption {
 		input.ServerSideEncryption = s3types.ServerSideEncryption(opt.ServerSideEncryption)
 	}
+	if opt.HasUserMetadata {
+		input.Metadata = opt.UserMetadata
+	}
+	if opt.HasCacheControl {
+		input.CacheControl = &opt.CacheControl
+	}
+	if opt.HasContentDisposition {
+		input.ContentDisposition = &opt.ContentDisposition
+	}
+	if opt.HasContentEncoding {
+		input.ContentEncoding = &opt.ContentEncoding
+	}
+	if opt.HasContentLanguage {
+		input.ContentLanguage = &opt.ContentLanguage
+	}
+	if opt.HasExpires {
+		input.Expires = &opt.Expires
+	}
+	if opt.HasTagging {
+		input.Tagging = formatTagging(opt.Tagging)
+	}
+	if opt.HasACL {
+		input.ACL = s3types.ObjectCannedACL(opt.ACL)
+	}
+	if opt.HasGrantFullControl {
+		input.GrantFullControl = &opt.GrantFullControl
+	}
+	if opt.HasGrantRead {
+		input.GrantRead = &opt.GrantRead
+	}
+	if opt.HasGrantReadAcp {
+		input.GrantReadACP = &opt.GrantReadAcp
+	}
+	if opt.HasGrantWriteAcp {
+		input.GrantWriteACP = &opt.GrantWriteAcp
+	}
+	if opt.HasObjectLockMode {
+		input.ObjectLockMode = s3types.ObjectLockMode(opt.ObjectLockMode)
+	}
+	if opt.HasObjectLockRetainUntilDate {
+		input.ObjectLockRetainUntilDate = &opt.ObjectLockRetainUntilDate
+	}
+	if opt.HasObjectLockLegalHold {
+		input.ObjectLockLegalHoldStatus = formatLegalHoldStatus(opt.ObjectLockLegalHold)
+	}
 
 	return
 }
@@ -452,6 +979,9 @@ func (s *Storage) formatDeleteObjectInput(path string, opt pairStorageDelete) (i
 	if opt.HasExceptedBucketOwner {
 		input.ExpectedBucketOwner = &opt.ExceptedBucketOwner
 	}
+	if opt.HasBypassGovernanceRetention {
+		input.BypassGovernanceRetention = opt.BypassGovernanceRetention
+	}
 
 	return
 }
@@ -464,6 +994,12 @@ func (s *Storage) formatCreateMultipartUploadInput(path string, opt pairStorageC
 		Key:    aws.String(rp),
 	}
 
+	if opt.HasContentType {
+		input.ContentType = &opt.ContentType
+	}
+	if opt.HasStorageClass {
+		input.StorageClass = s3types.StorageClass(opt.StorageClass)
+	}
 	if opt.HasServerSideEncryptionBucketKeyEnabled {
 		input.BucketKeyEnabled = opt.ServerSideEncryptionBucketKeyEnabled
 	}
@@ -486,6 +1022,51 @@ func (s *Storage) formatCreateMultipartUploadInput(path string, opt pairStorageC
 	if opt.HasServerSideEncryption {
 		input.ServerSideEncryption = s3types.ServerSideEncryption(opt.ServerSideEncryption)
 	}
+	if opt.HasUserMetadata {
+		input.Metadata = opt.UserMetadata
+	}
+	if opt.HasCacheControl {
+		input.CacheControl = &opt.CacheControl
+	}
+	if opt.HasContentDisposition {
+		input.ContentDisposition = &opt.ContentDisposition
+	}
+	if opt.HasContentEncoding {
+		input.ContentEncoding = &opt.ContentEncoding
+	}
+	if opt.HasContentLanguage {
+		input.ContentLanguage = &opt.ContentLanguage
+	}
+	if opt.HasExpires {
+		input.Expires = &opt.Expires
+	}
+	if opt.HasTagging {
+		input.Tagging = formatTagging(opt.Tagging)
+	}
+	if opt.HasACL {
+		input.ACL = s3types.ObjectCannedACL(opt.ACL)
+	}
+	if opt.HasGrantFullControl {
+		input.GrantFullControl = &opt.GrantFullControl
+	}
+	if opt.HasGrantRead {
+		input.GrantRead = &opt.GrantRead
+	}
+	if opt.HasGrantReadAcp {
+		input.GrantReadACP = &opt.GrantReadAcp
+	}
+	if opt.HasGrantWriteAcp {
+		input.GrantWriteACP = &opt.GrantWriteAcp
+	}
+	if opt.HasObjectLockMode {
+		input.ObjectLockMode = s3types.ObjectLockMode(opt.ObjectLockMode)
+	}
+	if opt.HasObjectLockRetainUntilDate {
+		input.ObjectLockRetainUntilDate = &opt.ObjectLockRetainUntilDate
+	}
+	if opt.HasObjectLockLegalHold {
+		input.ObjectLockLegalHoldStatus = formatLegalHoldStatus(opt.ObjectLockLegalHold)
+	}
 
 	return
 }
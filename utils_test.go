@@ -0,0 +1,235 @@
+package s3
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/beyondstorage/go-storage/v4/services"
+)
+
+func TestFormatError(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{"no such key", &s3types.NoSuchKey{}, services.ErrObjectNotExist},
+		{"not found", &s3types.NotFound{}, services.ErrObjectNotExist},
+		{"access denied", &smithy.GenericAPIError{Code: "AccessDenied", Message: "Access Denied"}, services.ErrPermissionDenied},
+		{"object locked", &smithy.GenericAPIError{Code: "AccessDenied", Message: "Access Denied because object protected by object lock."}, ErrObjectLocked},
+		{"not modified", &smithy.GenericAPIError{Code: "NotModified"}, ErrObjectNotModified},
+		{"precondition failed", &smithy.GenericAPIError{Code: "PreconditionFailed"}, ErrPreconditionFailed},
+		{"invalid object state", &s3types.InvalidObjectState{}, ErrObjectArchived},
+		{"restore already in progress", &smithy.GenericAPIError{Code: "RestoreAlreadyInProgress"}, ErrRestoreInProgress},
+		{"internal error", &smithy.GenericAPIError{Code: "InternalError"}, services.ErrUnexpected},
+		{"wrapped", &smithy.OperationError{ServiceID: "S3", OperationName: "GetObject", Err: &s3types.NoSuchKey{}}, services.ErrObjectNotExist},
+		{"not api error", errors.New("connection reset"), services.ErrUnexpected},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := formatError(tt.err)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, actual %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestParseRestore(t *testing.T) {
+	cases := []struct {
+		name       string
+		input      string
+		ongoing    bool
+		expiryDate time.Time
+		hasErr     bool
+	}{
+		{"ongoing", `ongoing-request="true"`, true, time.Time{}, false},
+		{"completed", `ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`, false, time.Date(2012, 12, 21, 0, 0, 0, 0, time.UTC), false},
+		{"invalid expiry date", `ongoing-request="false", expiry-date="2012-12-21"`, false, time.Time{}, true},
+		{"unterminated value", `ongoing-request="false`, false, time.Time{}, true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ongoing, expiryDate, err := parseRestore(tt.input)
+			if tt.hasErr {
+				if err == nil {
+					t.Errorf("expected error, actual nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse restore: %v", err)
+			}
+			if ongoing != tt.ongoing {
+				t.Errorf("ongoing: expected %v, actual %v", tt.ongoing, ongoing)
+			}
+			if !expiryDate.Equal(tt.expiryDate) {
+				t.Errorf("expiry date: expected %v, actual %v", tt.expiryDate, expiryDate)
+			}
+		})
+	}
+}
+
+func TestCalculatePartSize(t *testing.T) {
+	cases := []struct {
+		name     string
+		size     int64
+		expected int64
+	}{
+		{"6GB", 6 * 1024 * 1024 * 1024, copyPartSize},
+		{"5TB", 5 * 1024 * 1024 * 1024 * 1024, 549755814},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			partSize := calculatePartSize(tt.size, copyPartSize)
+			if partSize != tt.expected {
+				t.Errorf("expected %d, actual %d", tt.expected, partSize)
+			}
+			if (tt.size+partSize-1)/partSize > multipartNumberMaximum {
+				t.Errorf("part count exceeds %d", multipartNumberMaximum)
+			}
+		})
+	}
+}
+
+func TestPartContinuationToken(t *testing.T) {
+	cases := []struct {
+		name           string
+		keyMarker      string
+		uploadIdMarker string
+	}{
+		{"simple key", "abc", "upload-id"},
+		{"key with slash", "a/b/c", "upload-id"},
+		{"key only", "a/b/c/", ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			input := &objectPageStatus{keyMarker: tt.keyMarker, uploadIdMarker: tt.uploadIdMarker}
+
+			output := &objectPageStatus{}
+			output.setPartContinuationToken(input.ContinuationToken())
+			if output.keyMarker != tt.keyMarker || output.uploadIdMarker != tt.uploadIdMarker {
+				t.Errorf("expected %s/%s, actual %s/%s", tt.keyMarker, tt.uploadIdMarker, output.keyMarker, output.uploadIdMarker)
+			}
+		})
+	}
+}
+
+func TestFormatPutObjectInput(t *testing.T) {
+	s := &Storage{name: "bucket", workDir: "/"}
+	expires := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		opt      pairStorageWrite
+		actual   func(input *s3.PutObjectInput) string
+		expected string
+	}{
+		{"content type", pairStorageWrite{HasContentType: true, ContentType: "text/plain"},
+			func(input *s3.PutObjectInput) string { return aws.ToString(input.ContentType) }, "text/plain"},
+		{"cache control", pairStorageWrite{HasCacheControl: true, CacheControl: "no-cache"},
+			func(input *s3.PutObjectInput) string { return aws.ToString(input.CacheControl) }, "no-cache"},
+		{"content disposition", pairStorageWrite{HasContentDisposition: true, ContentDisposition: "attachment"},
+			func(input *s3.PutObjectInput) string { return aws.ToString(input.ContentDisposition) }, "attachment"},
+		{"content encoding", pairStorageWrite{HasContentEncoding: true, ContentEncoding: "gzip"},
+			func(input *s3.PutObjectInput) string { return aws.ToString(input.ContentEncoding) }, "gzip"},
+		{"content language", pairStorageWrite{HasContentLanguage: true, ContentLanguage: "en-US"},
+			func(input *s3.PutObjectInput) string { return aws.ToString(input.ContentLanguage) }, "en-US"},
+		{"expires", pairStorageWrite{HasExpires: true, Expires: expires},
+			func(input *s3.PutObjectInput) string { return aws.ToTime(input.Expires).String() }, expires.String()},
+		{"not set", pairStorageWrite{},
+			func(input *s3.PutObjectInput) string {
+				if input.ContentType != nil || input.CacheControl != nil || input.ContentDisposition != nil ||
+					input.ContentEncoding != nil || input.ContentLanguage != nil || input.Expires != nil {
+					return "set"
+				}
+				return ""
+			}, ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := s.formatPutObjectInput("a", 1, tt.opt)
+			if err != nil {
+				t.Fatalf("format: %v", err)
+			}
+			if v := tt.actual(input); v != tt.expected {
+				t.Errorf("expected %s, actual %s", tt.expected, v)
+			}
+		})
+	}
+}
+
+func TestFormatCreateMultipartUploadInput(t *testing.T) {
+	s := &Storage{name: "bucket", workDir: "/"}
+
+	input, err := s.formatCreateMultipartUploadInput("a", pairStorageCreateMultipart{
+		HasContentType:  true,
+		ContentType:     "text/plain",
+		HasStorageClass: true,
+		StorageClass:    "STANDARD_IA",
+	})
+	if err != nil {
+		t.Fatalf("format: %v", err)
+	}
+	if v := aws.ToString(input.ContentType); v != "text/plain" {
+		t.Errorf("content type: expected text/plain, actual %s", v)
+	}
+	if v := input.StorageClass; v != s3types.StorageClassStandardIa {
+		t.Errorf("storage class: expected %s, actual %s", s3types.StorageClassStandardIa, v)
+	}
+}
+
+func TestFormatGetObjectInput(t *testing.T) {
+	s := &Storage{name: "bucket", workDir: "/"}
+	expires := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		opt      pairStorageRead
+		actual   func(input *s3.GetObjectInput) string
+		expected string
+	}{
+		{"response cache control", pairStorageRead{HasResponseCacheControl: true, ResponseCacheControl: "no-cache"},
+			func(input *s3.GetObjectInput) string { return aws.ToString(input.ResponseCacheControl) }, "no-cache"},
+		{"response content disposition", pairStorageRead{HasResponseContentDisposition: true, ResponseContentDisposition: "attachment"},
+			func(input *s3.GetObjectInput) string { return aws.ToString(input.ResponseContentDisposition) }, "attachment"},
+		{"response content encoding", pairStorageRead{HasResponseContentEncoding: true, ResponseContentEncoding: "gzip"},
+			func(input *s3.GetObjectInput) string { return aws.ToString(input.ResponseContentEncoding) }, "gzip"},
+		{"response content language", pairStorageRead{HasResponseContentLanguage: true, ResponseContentLanguage: "en-US"},
+			func(input *s3.GetObjectInput) string { return aws.ToString(input.ResponseContentLanguage) }, "en-US"},
+		{"response content type", pairStorageRead{HasResponseContentType: true, ResponseContentType: "text/plain"},
+			func(input *s3.GetObjectInput) string { return aws.ToString(input.ResponseContentType) }, "text/plain"},
+		{"response expires", pairStorageRead{HasResponseExpires: true, ResponseExpires: expires},
+			func(input *s3.GetObjectInput) string { return aws.ToTime(input.ResponseExpires).String() }, expires.String()},
+		{"not set", pairStorageRead{},
+			func(input *s3.GetObjectInput) string {
+				if input.ResponseCacheControl != nil || input.ResponseContentDisposition != nil || input.ResponseContentEncoding != nil ||
+					input.ResponseContentLanguage != nil || input.ResponseContentType != nil || input.ResponseExpires != nil {
+					return "set"
+				}
+				return ""
+			}, ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := s.formatGetObjectInput("a", tt.opt)
+			if err != nil {
+				t.Fatalf("format: %v", err)
+			}
+			if v := tt.actual(input); v != tt.expected {
+				t.Errorf("expected %s, actual %s", tt.expected, v)
+			}
+		})
+	}
+}
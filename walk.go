@@ -0,0 +1,251 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/beyondstorage/go-storage/v4/services"
+	. "github.com/beyondstorage/go-storage/v4/types"
+)
+
+// defaultWalkConcurrency is the default number of prefixes listed concurrently by Walk.
+const defaultWalkConcurrency = 8
+
+// WalkFunc is the callback of Walk, which will be called for every object found.
+//
+// WalkFunc will be called concurrently from multiple goroutines, send the objects to a channel if they
+// need to be processed in order. The walk will be stopped if WalkFunc returns an error.
+type WalkFunc func(o *Object) error
+
+// pairStorageWalk is the parsed struct for Walk.
+type pairStorageWalk struct {
+	pairs []Pair
+	// Optional pairs
+	HasConcurrency         bool
+	Concurrency            int
+	HasExceptedBucketOwner bool
+	ExceptedBucketOwner    string
+	HasExclude             bool
+	Exclude                string
+	HasInclude             bool
+	Include                string
+}
+
+func (s *Storage) parsePairStorageWalk(opts []Pair) (pairStorageWalk, error) {
+	result := pairStorageWalk{pairs: opts}
+
+	for _, v := range opts {
+		switch v.Key {
+		case "concurrency":
+			if result.HasConcurrency {
+				continue
+			}
+			result.HasConcurrency = true
+			result.Concurrency = v.Value.(int)
+		case "excepted_bucket_owner":
+			if result.HasExceptedBucketOwner {
+				continue
+			}
+			result.HasExceptedBucketOwner = true
+			result.ExceptedBucketOwner = v.Value.(string)
+		case "exclude":
+			if result.HasExclude {
+				continue
+			}
+			result.HasExclude = true
+			result.Exclude = v.Value.(string)
+		case "include":
+			if result.HasInclude {
+				continue
+			}
+			result.HasInclude = true
+			result.Include = v.Value.(string)
+		default:
+			return pairStorageWalk{}, services.PairUnsupportedError{Pair: v}
+		}
+	}
+
+	return result, nil
+}
+
+// Walk will call fn for every object under the dir path.
+//
+// Common prefixes are discovered with delimiter listing, and sub-prefixes will be listed concurrently,
+// use WithConcurrency to control the concurrency, 8 by default. Dir markers will not be passed to fn.
+//
+// Use WithInclude and WithExclude to filter objects with glob patterns supported by path.Match. Patterns
+// without "/" are matched against the base name of objects, so that "*.log" matches "a/b.log", others
+// are matched against the whole path. Dirs matching the exclude pattern will be skipped entirely.
+//
+// The error returned by fn will be returned as is, and listing errors will contain the prefix that failed.
+func (s *Storage) Walk(path string, fn WalkFunc, pairs ...Pair) (err error) {
+	ctx := context.Background()
+	return s.WalkWithContext(ctx, path, fn, pairs...)
+}
+
+// WalkWithContext will call fn for every object under the dir path.
+//
+// Common prefixes are discovered with delimiter listing, and sub-prefixes will be listed concurrently,
+// use WithConcurrency to control the concurrency, 8 by default. Dir markers will not be passed to fn.
+//
+// Use WithInclude and WithExclude to filter objects with glob patterns supported by path.Match. Patterns
+// without "/" are matched against the base name of objects, so that "*.log" matches "a/b.log", others
+// are matched against the whole path. Dirs matching the exclude pattern will be skipped entirely.
+//
+// The error returned by fn will be returned as is, and listing errors will contain the prefix that failed.
+// The walk will be stopped once ctx is canceled, and ctx.Err() will be returned.
+func (s *Storage) WalkWithContext(ctx context.Context, path string, fn WalkFunc, pairs ...Pair) (err error) {
+	opt, err := s.parsePairStorageWalk(pairs)
+	if err != nil {
+		return s.formatError("walk", err, path)
+	}
+	return s.walk(ctx, strings.ReplaceAll(path, "\\", "/"), fn, opt)
+}
+
+func (s *Storage) walk(ctx context.Context, p string, fn WalkFunc, opt pairStorageWalk) (err error) {
+	for _, pattern := range []string{opt.Include, opt.Exclude} {
+		// path.Match only reports ErrBadPattern while matching, check the patterns before walking.
+		if _, err = path.Match(pattern, ""); err != nil {
+			return s.formatError("walk", fmt.Errorf("pattern %q: %v: %w", pattern, err, services.ErrRestrictionDissatisfied), p)
+		}
+	}
+
+	concurrency := defaultWalkConcurrency
+	if opt.HasConcurrency && opt.Concurrency > 0 {
+		concurrency = opt.Concurrency
+	}
+
+	// Walk the path as a dir, so that "dir" will not match "dir2/".
+	if p != "" && !strings.HasSuffix(p, "/") {
+		p += "/"
+	}
+
+	wctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w := &walker{
+		s:      s,
+		ctx:    wctx,
+		cancel: cancel,
+		fn:     fn,
+		opt:    opt,
+		sem:    make(chan struct{}, concurrency),
+	}
+	// The caller's goroutine takes the first slot.
+	w.sem <- struct{}{}
+	w.walk(p)
+	w.wg.Wait()
+
+	// The prefix failed first could be caused by cancellation, return the reason instead.
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return w.err
+}
+
+// walker holds the state shared by all goroutines of a walk.
+type walker struct {
+	s      *Storage
+	ctx    context.Context
+	cancel context.CancelFunc
+	fn     WalkFunc
+	opt    pairStorageWalk
+
+	// sem limits the number of goroutines listing prefixes.
+	sem chan struct{}
+	wg  sync.WaitGroup
+
+	once sync.Once
+	err  error
+}
+
+// fail will record the first error and stop all other goroutines.
+func (w *walker) fail(err error) {
+	w.once.Do(func() {
+		w.err = err
+		w.cancel()
+	})
+}
+
+// spawn will list the prefix in a new goroutine if there is a free slot.
+func (w *walker) spawn(prefix string) {
+	select {
+	case w.sem <- struct{}{}:
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			defer func() { <-w.sem }()
+
+			w.walk(prefix)
+		}()
+	default:
+		// All slots are taken, list the prefix in current goroutine instead of waiting.
+		w.walk(prefix)
+	}
+}
+
+func (w *walker) walk(prefix string) {
+	it, err := w.s.list(w.ctx, prefix, pairStorageList{
+		HasListMode:            true,
+		ListMode:               ListModeDir,
+		HasMaxKeys:             true,
+		MaxKeys:                listMaxKeysMaximum,
+		HasExceptedBucketOwner: w.opt.HasExceptedBucketOwner,
+		ExceptedBucketOwner:    w.opt.ExceptedBucketOwner,
+	})
+	if err != nil {
+		w.fail(w.s.formatError("walk", err, prefix))
+		return
+	}
+
+	for {
+		o, err := it.Next()
+		if err != nil {
+			if !errors.Is(err, IterateDone) {
+				w.fail(w.s.formatError("walk", err, prefix))
+			}
+			return
+		}
+		if w.ctx.Err() != nil {
+			return
+		}
+
+		if o.Mode.IsDir() {
+			if w.opt.HasExclude && matchPattern(w.opt.Exclude, strings.TrimSuffix(o.Path, "/")) {
+				continue
+			}
+			w.spawn(o.Path)
+			continue
+		}
+		// The marker of the dir itself will be listed under its prefix.
+		if o.Path == prefix {
+			continue
+		}
+		if w.opt.HasInclude && !matchPattern(w.opt.Include, o.Path) {
+			continue
+		}
+		if w.opt.HasExclude && matchPattern(w.opt.Exclude, o.Path) {
+			continue
+		}
+
+		if err = w.fn(o); err != nil {
+			w.fail(err)
+			return
+		}
+	}
+}
+
+// matchPattern reports whether p matches the glob pattern, patterns have been checked before.
+//
+// path.Match doesn't allow "*" to match "/", match the base name for patterns without "/" instead.
+func matchPattern(pattern, p string) bool {
+	if !strings.Contains(pattern, "/") {
+		p = path.Base(p)
+	}
+	ok, _ := path.Match(pattern, p)
+	return ok
+}